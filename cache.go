@@ -0,0 +1,238 @@
+package golyrics
+
+import (
+	"container/list"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default time-to-live of cached lookups, see WithCache.
+const (
+	DefaultCacheTTL         = 24 * time.Hour
+	DefaultNegativeCacheTTL = time.Hour
+)
+
+// Cache stores the encoded results of lookups by key.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored under key, if it did not expire.
+	Get(key string) ([]byte, bool)
+	// Set stores value under key for ttl. A ttl of zero or less never expires.
+	Set(key string, value []byte, ttl time.Duration)
+	// Delete removes the value stored under key, if any.
+	Delete(key string)
+}
+
+// WithCache makes the Client keep search results and lyrics in cache
+// for DefaultCacheTTL, and remember lookups that found nothing
+// for DefaultNegativeCacheTTL.
+func WithCache(cache Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// WithCacheTTL sets how long the Client keeps lookups in its cache:
+// ttl for results and negativeTTL for lookups that found nothing.
+// A negativeTTL below zero disables negative caching.
+func WithCacheTTL(ttl, negativeTTL time.Duration) Option {
+	return func(c *Client) {
+		c.cacheTTL = ttl
+		c.negativeCacheTTL = negativeTTL
+	}
+}
+
+// cacheEntry is how lookups are encoded in a Cache.
+type cacheEntry struct {
+	Tracks   []Track `json:",omitempty"`
+	Track    *Track  `json:",omitempty"`
+	NotFound bool    `json:",omitempty"`
+	// Stub is set for lookups that only found a stub, see ErrStub.
+	Stub bool `json:",omitempty"`
+	// Provider is the provider that answered.
+	Provider string `json:",omitempty"`
+	// Validators are the validators of the pages the lyrics came from,
+	// by URL, for entries kept to be revalidated.
+	Validators map[string]validator `json:",omitempty"`
+	// Expires is when an entry kept to be revalidated expires,
+	// in Unix nanoseconds.
+	Expires int64 `json:",omitempty"`
+}
+
+// stale reports whether entry must be revalidated before being used.
+func (entry *cacheEntry) stale(now time.Time) bool {
+	return entry.Expires != 0 && now.UnixNano() >= entry.Expires
+}
+
+// normalizeKey makes equivalent queries share a cache key by ignoring case,
+// surrounding spaces and the difference between spaces and underscores.
+func normalizeKey(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(strings.Replace(s, "_", " ", -1))), " ")
+}
+
+// cacheIdentifier is implemented by providers whose answers depend on more
+// than their name, like the site they query or the providers they combine.
+type cacheIdentifier interface {
+	// cacheID identifies the answers of the provider in cache keys.
+	cacheID() string
+}
+
+// cacheID returns what identifies the answers of provider in cache keys,
+// its name unless it implements cacheIdentifier.
+func cacheID(provider Provider) string {
+	if identifier, ok := provider.(cacheIdentifier); ok {
+		return identifier.cacheID()
+	}
+	return provider.Name()
+}
+
+// cacheIDs returns the cache IDs of providers, in a list named after combined.
+func cacheIDs(combined string, providers []Provider) string {
+	ids := make([]string, len(providers))
+	for i, provider := range providers {
+		ids[i] = cacheID(provider)
+	}
+	return combined + "(" + strings.Join(ids, ",") + ")"
+}
+
+func (c *Client) searchCacheKey(query string) string {
+	return "search\x00" + cacheID(c.provider) + "\x00" + normalizeKey(query)
+}
+
+func (c *Client) lyricsCacheKey(track *Track) string {
+	return "lyrics\x00" + cacheID(c.provider) + "\x00" + normalizeKey(track.Artist) + "\x00" + normalizeKey(track.Name)
+}
+
+func (c *Client) cacheGet(key string) (*cacheEntry, bool) {
+	if c.cache == nil {
+		return nil, false
+	}
+	data, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(data, entry); err != nil {
+		c.cache.Delete(key)
+		return nil, false
+	}
+	return entry, true
+}
+
+func (c *Client) cacheSet(key string, entry *cacheEntry) {
+	if c.cache == nil {
+		return
+	}
+	ttl := c.cacheTTL
+	if entry.NotFound || entry.Track == nil && len(entry.Tracks) == 0 {
+		if c.negativeCacheTTL < 0 {
+			return
+		}
+		ttl = c.negativeCacheTTL
+	}
+	entry.Expires = 0
+	if len(entry.Validators) > 0 && c.revalidationWindow > 0 && ttl > 0 {
+		entry.Expires = time.Now().Add(ttl).UnixNano()
+		ttl += c.revalidationWindow
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	c.cache.Set(key, data, ttl)
+}
+
+// MemoryCache is an in-memory Cache evicting the least recently used values
+// when it holds too many of them or when they take too much space.
+type MemoryCache struct {
+	maxEntries int
+	maxBytes   int64
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	bytes   int64
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache creates a MemoryCache holding at most maxEntries values
+// taking at most maxBytes together. A limit of zero or less means no limit.
+func NewMemoryCache(maxEntries int, maxBytes int64) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		now:        time.Now,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+	}
+}
+
+// Get returns the value stored under key, if it did not expire.
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*memoryEntry)
+	if !entry.expires.IsZero() && !m.now().Before(entry.expires) {
+		m.remove(element)
+		return nil, false
+	}
+	m.lru.MoveToFront(element)
+	return entry.value, true
+}
+
+// Set stores value under key for ttl, evicting other values if needed.
+// A ttl of zero or less never expires.
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if element, ok := m.entries[key]; ok {
+		m.remove(element)
+	}
+	if m.maxBytes > 0 && int64(len(value)) > m.maxBytes {
+		return
+	}
+	entry := &memoryEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expires = m.now().Add(ttl)
+	}
+	m.entries[key] = m.lru.PushFront(entry)
+	m.bytes += int64(len(value))
+	for m.maxEntries > 0 && m.lru.Len() > m.maxEntries || m.maxBytes > 0 && m.bytes > m.maxBytes {
+		m.remove(m.lru.Back())
+	}
+}
+
+// Delete removes the value stored under key, if any.
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if element, ok := m.entries[key]; ok {
+		m.remove(element)
+	}
+}
+
+// Len returns the number of values in the cache, including expired ones
+// that were not evicted yet.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lru.Len()
+}
+
+func (m *MemoryCache) remove(element *list.Element) {
+	entry := m.lru.Remove(element).(*memoryEntry)
+	delete(m.entries, entry.key)
+	m.bytes -= int64(len(entry.value))
+}
@@ -0,0 +1,306 @@
+package golyrics
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// countingProvider counts the lookups that reached it.
+type countingProvider struct {
+	staticProvider
+	searches int
+	fetches  int
+}
+
+func (p *countingProvider) Search(ctx context.Context, query string) ([]Track, error) {
+	p.searches++
+	return p.staticProvider.Search(ctx, query)
+}
+
+func (p *countingProvider) Fetch(ctx context.Context, track *Track) error {
+	p.fetches++
+	if p.lyrics == "" && p.err == nil {
+		return ErrNotFound
+	}
+	return p.staticProvider.Fetch(ctx, track)
+}
+
+func TestMemoryCache(t *testing.T) {
+	now := time.Date(2018, 6, 30, 12, 0, 0, 0, time.UTC)
+	cache := NewMemoryCache(2, 10)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("a", []byte("1"), time.Minute)
+	cache.Set("b", []byte("2"), 0)
+	cache.Get("a")
+	cache.Set("c", []byte("3"), time.Hour)
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("MemoryCache kept the least recently used value beyond its entry limit")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("MemoryCache returned an expired value")
+	}
+	if value, ok := cache.Get("c"); !ok || string(value) != "3" {
+		t.Errorf("MemoryCache.Get() = %q, %v, want %q, true", value, ok, "3")
+	}
+
+	cache.Set("big", []byte("1234567890"), 0)
+	if _, ok := cache.Get("c"); ok || cache.Len() != 1 {
+		t.Errorf("MemoryCache kept %d values beyond its size limit", cache.Len())
+	}
+	cache.Set("huge", []byte("12345678901"), 0)
+	if _, ok := cache.Get("huge"); ok {
+		t.Errorf("MemoryCache stored a value larger than its size limit")
+	}
+
+	cache.Delete("big")
+	if cache.Len() != 0 {
+		t.Errorf("MemoryCache.Delete() left %d values", cache.Len())
+	}
+}
+
+func TestClient_Cache(t *testing.T) {
+	provider := &countingProvider{staticProvider: staticProvider{
+		name:   "counting",
+		tracks: []Track{{Artist: "Blackfield", Name: "Pain"}},
+		lyrics: "Pain",
+	}}
+	client := NewClient(WithProviderInstance(provider), WithCache(NewMemoryCache(100, 0)))
+
+	for _, query := range []string{"Blackfield:Pain", "  blackfield:pain", "blackfield:PAIN"} {
+		got, err := client.Search(query)
+		if err != nil || !reflect.DeepEqual(got, provider.tracks) {
+			t.Errorf("Client.Search(%q) = %v, %v, want %v, nil", query, got, err, provider.tracks)
+		}
+	}
+	if provider.searches != 1 {
+		t.Errorf("Client.Search() reached the provider %d times, want 1", provider.searches)
+	}
+
+	for i, track := range []Track{{Artist: "Blackfield", Name: "Pain"}, {Artist: "blackfield", Name: "pain"}} {
+		result, err := client.FetchResult(context.Background(), &track)
+		if err != nil || track.Lyrics != "Pain" {
+			t.Errorf("Client.FetchResult() lyrics = %q, %v, want %q, nil", track.Lyrics, err, "Pain")
+			continue
+		}
+		if wantCached := i > 0; result.Cached != wantCached || result.Provider != "counting" {
+			t.Errorf("Client.FetchResult() = %+v, want Cached %v from counting", result, wantCached)
+		}
+	}
+	if provider.fetches != 1 {
+		t.Errorf("Client.Fetch() reached the provider %d times, want 1", provider.fetches)
+	}
+}
+
+func TestClient_NegativeCache(t *testing.T) {
+	tests := []struct {
+		name        string
+		negativeTTL time.Duration
+		wantFetches int
+	}{
+		{
+			name:        "should remember lyrics that were not found",
+			negativeTTL: time.Minute,
+			wantFetches: 1,
+		},
+		{
+			name:        "should not remember missing lyrics when negative caching is disabled",
+			negativeTTL: -1,
+			wantFetches: 2,
+		},
+	}
+	for _, tt := range tests {
+		provider := &countingProvider{staticProvider: staticProvider{name: "empty"}}
+		client := NewClient(
+			WithProviderInstance(provider),
+			WithCache(NewMemoryCache(0, 0)),
+			WithCacheTTL(time.Hour, tt.negativeTTL),
+		)
+		for i := 0; i < 2; i++ {
+			if err := client.Fetch(&Track{Artist: "Nobody", Name: "Nothing"}); !errors.Is(err, ErrNotFound) {
+				t.Errorf("%q. Client.Fetch() error = %v, want %v", tt.name, err, ErrNotFound)
+			}
+		}
+		if provider.fetches != tt.wantFetches {
+			t.Errorf("%q. Client.Fetch() reached the provider %d times, want %d", tt.name, provider.fetches, tt.wantFetches)
+		}
+	}
+}
+
+// albumProvider sets the album of the tracks it fetches
+// and renames those it knows under another name.
+type albumProvider struct {
+	staticProvider
+	album   string
+	renamed map[string]string
+}
+
+func (p *albumProvider) Fetch(ctx context.Context, track *Track) error {
+	if name, ok := p.renamed[track.Name]; ok {
+		track.Name = name
+	}
+	track.Album = p.album
+	return p.staticProvider.Fetch(ctx, track)
+}
+
+func TestClient_CacheKeepsCallerFields(t *testing.T) {
+	provider := &albumProvider{
+		staticProvider: staticProvider{name: "album", lyrics: "Pain"},
+		album:          "Blackfield",
+		renamed:        map[string]string{"Once Again": "Once"},
+	}
+	client := NewClient(WithProviderInstance(provider), WithCache(NewMemoryCache(0, 0)))
+	first := &SyncedLyrics{Lines: []SyncedLine{{Start: time.Second, Text: "first"}}}
+	second := &SyncedLyrics{Lines: []SyncedLine{{Start: time.Second, Text: "second"}}}
+
+	tests := []struct {
+		name  string
+		track Track
+		want  Track
+	}{
+		{
+			name:  "should fetch the lyrics",
+			track: Track{Artist: "Blackfield", Name: "Pain", Year: 2004, Synced: first},
+			want:  Track{Artist: "Blackfield", Name: "Pain", Lyrics: "Pain", Album: "Blackfield", Year: 2004, Synced: first},
+		},
+		{
+			name:  "should keep the fields of the caller on cache hits",
+			track: Track{Artist: "blackfield", Name: "pain", Synced: second},
+			want:  Track{Artist: "blackfield", Name: "pain", Lyrics: "Pain", Album: "Blackfield", Synced: second},
+		},
+		{
+			name:  "should rename tracks found under another name",
+			track: Track{Artist: "Blackfield", Name: "Once Again"},
+			want:  Track{Artist: "Blackfield", Name: "Once", Lyrics: "Pain", Album: "Blackfield"},
+		},
+		{
+			name:  "should rename tracks found under another name on cache hits",
+			track: Track{Artist: "blackfield", Name: "once again"},
+			want:  Track{Artist: "Blackfield", Name: "Once", Lyrics: "Pain", Album: "Blackfield"},
+		},
+	}
+	for _, tt := range tests {
+		track := tt.track
+		if err := client.Fetch(&track); err != nil {
+			t.Errorf("%q. Client.Fetch() error = %v", tt.name, err)
+			continue
+		}
+		track.Provider, track.FetchedAt = "", time.Time{}
+		if !reflect.DeepEqual(track, tt.want) {
+			t.Errorf("%q. Client.Fetch() = %+v, want %+v", tt.name, track, tt.want)
+		}
+	}
+}
+
+func TestClient_CacheKeys(t *testing.T) {
+	first := &staticProvider{name: "first"}
+	second := &staticProvider{name: "second"}
+	tests := []struct {
+		name      string
+		a, b      []Option
+		wantShare bool
+	}{
+		{
+			name:      "should share keys between clients of the same site",
+			a:         []Option{WithLyricsBaseURI("http://mirror/wiki/")},
+			b:         []Option{WithLyricsBaseURI("http://mirror/wiki/")},
+			wantShare: true,
+		},
+		{
+			name: "should separate wikia mirrors",
+			a:    []Option{WithLyricsBaseURI("http://mirror/wiki/")},
+			b:    []Option{WithLyricsBaseURI("http://other/wiki/")},
+		},
+		{
+			name: "should separate mediawiki mirrors",
+			a:    []Option{WithProvider("mediawiki"), WithAPIURI("http://mirror/api.php")},
+			b:    []Option{WithProvider("mediawiki"), WithAPIURI("http://other/api.php")},
+		},
+		{
+			name: "should separate formatted lyrics",
+			a:    []Option{WithFormatting(true)},
+			b:    []Option{WithFormatting(false)},
+		},
+		{
+			name: "should separate chains of other providers",
+			a:    []Option{WithProviderInstance(NewChain(first, second))},
+			b:    []Option{WithProviderInstance(NewChain(first))},
+		},
+		{
+			name: "should separate races of other providers",
+			a:    []Option{WithProviderInstance(NewRace(RaceConfig{}, first, second))},
+			b:    []Option{WithProviderInstance(NewRace(RaceConfig{}, second))},
+		},
+	}
+	track := &Track{Artist: "Blackfield", Name: "Pain"}
+	for _, tt := range tests {
+		a, b := NewClient(tt.a...), NewClient(tt.b...)
+		if got := a.searchCacheKey("pain") == b.searchCacheKey("pain"); got != tt.wantShare {
+			t.Errorf("%q. Client.searchCacheKey() shared = %v, want %v", tt.name, got, tt.wantShare)
+		}
+		if got := a.lyricsCacheKey(track) == b.lyricsCacheKey(track); got != tt.wantShare {
+			t.Errorf("%q. Client.lyricsCacheKey() shared = %v, want %v", tt.name, got, tt.wantShare)
+		}
+	}
+}
+
+func TestClient_CacheSkipsFailures(t *testing.T) {
+	provider := &countingProvider{staticProvider: staticProvider{name: "failing", err: ErrUpstream}}
+	client := NewClient(WithProviderInstance(provider), WithCache(NewMemoryCache(0, 0)))
+	for i := 0; i < 2; i++ {
+		client.Fetch(&Track{Artist: "Blackfield", Name: "Pain"})
+		client.Search("blackfield:pain")
+	}
+	if provider.fetches != 2 || provider.searches != 2 {
+		t.Errorf("Client cached failed lookups: %d fetches and %d searches reached the provider", provider.fetches, provider.searches)
+	}
+}
+
+func TestClient_CacheSkipsPlaceholders(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	client := newTestClient(server, WithCache(NewMemoryCache(0, 0)))
+
+	tests := []struct {
+		name    string
+		track   Track
+		wantErr error
+	}{
+		{
+			name:    "should not cache licensing notices",
+			track:   Track{Artist: "Licensed", Name: "Song"},
+			wantErr: ErrNotLicensed,
+		},
+		{
+			name:    "should not cache truncated lyrics",
+			track:   Track{Artist: "Truncated", Name: "Song"},
+			wantErr: ErrTruncated,
+		},
+		{
+			name:    "should not cache instrumental markers",
+			track:   Track{Artist: "Instrumental", Name: "Song"},
+			wantErr: ErrInstrumental,
+		},
+		{
+			name:    "should cache stubs as stubs",
+			track:   Track{Artist: "Stub", Name: "Song"},
+			wantErr: ErrStub,
+		},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 2; i++ {
+			track := tt.track
+			if err := client.Fetch(&track); !errors.Is(err, tt.wantErr) {
+				t.Errorf("%q. Client.Fetch() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if track.Lyrics != "" {
+				t.Errorf("%q. Client.Fetch() lyrics = %q, want none", tt.name, track.Lyrics)
+			}
+		}
+	}
+}
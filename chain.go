@@ -0,0 +1,147 @@
+package golyrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const chainProviderName = "chain"
+
+// ProviderError is the error a single provider returned during a lookup.
+type ProviderError struct {
+	Provider string
+	Err      error
+}
+
+func (e ProviderError) Error() string {
+	return e.Provider + ": " + e.Err.Error()
+}
+
+// Result describes how the lyrics of a track were found.
+type Result struct {
+	// Provider is the name of the provider that answered.
+	Provider string
+	// Failures holds why each provider tried before it did not answer.
+	Failures []ProviderError
+	// Score is the score of the answer when it was picked by a Race.
+	Score float64
+	// Cached reports whether the answer came from the Client's cache.
+	Cached bool
+	// Revalidated reports whether the cached answer was confirmed
+	// to be up to date by a conditional request.
+	Revalidated bool
+}
+
+// ResultFetcher is implemented by providers that can report
+// which of their sources answered a fetch.
+type ResultFetcher interface {
+	FetchResult(ctx context.Context, track *Track) (*Result, error)
+}
+
+// ChainError is returned when every provider of a Chain or a Race failed.
+type ChainError struct {
+	Failures []ProviderError
+}
+
+func (e *ChainError) Error() string {
+	if len(e.Failures) == 0 {
+		return "golyrics: no providers to try"
+	}
+	reasons := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		reasons[i] = failure.Error()
+	}
+	return fmt.Sprintf("golyrics: all providers failed: %s", strings.Join(reasons, "; "))
+}
+
+// Is reports whether every provider failed with an error matching target,
+// so that errors.Is(err, ErrNotFound) holds when none of them found the lyrics.
+func (e *ChainError) Is(target error) bool {
+	if len(e.Failures) == 0 {
+		return false
+	}
+	for _, failure := range e.Failures {
+		if !errors.Is(failure.Err, target) {
+			return false
+		}
+	}
+	return true
+}
+
+// Chain is a Provider that tries its providers in priority order,
+// falling through to the next one when a provider fails or finds nothing.
+type Chain struct {
+	providers []Provider
+}
+
+// NewChain creates a Chain trying providers in the given order.
+func NewChain(providers ...Provider) *Chain {
+	return &Chain{providers: providers}
+}
+
+// Name returns "chain".
+func (c *Chain) Name() string {
+	return chainProviderName
+}
+
+func (c *Chain) cacheID() string {
+	return cacheIDs(chainProviderName, c.providers)
+}
+
+// Search returns the tracks found by the first provider with any results.
+func (c *Chain) Search(ctx context.Context, query string) ([]Track, error) {
+	var failures []ProviderError
+	for _, provider := range c.providers {
+		tracks, err := provider.Search(ctx, query)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			failures = append(failures, ProviderError{Provider: provider.Name(), Err: err})
+			continue
+		}
+		if len(tracks) > 0 {
+			return tracks, nil
+		}
+	}
+	if len(failures) == len(c.providers) {
+		return nil, &ChainError{Failures: failures}
+	}
+	return []Track{}, nil
+}
+
+// Fetch sets the lyrics of track from the first provider that has them.
+func (c *Chain) Fetch(ctx context.Context, track *Track) error {
+	_, err := c.FetchResult(ctx, track)
+	return err
+}
+
+// FetchResult is like Fetch but also reports which provider answered
+// and why each provider tried before it failed.
+// If all of them fail, the error is a *ChainError.
+func (c *Chain) FetchResult(ctx context.Context, track *Track) (*Result, error) {
+	result := &Result{}
+	for _, provider := range c.providers {
+		candidate := track.clone()
+		err := provider.Fetch(ctx, &candidate)
+		if err == nil && candidate.Lyrics == "" {
+			err = ErrNotFound
+		}
+		if errors.Is(err, ErrNotModified) {
+			return nil, err
+		}
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			result.Failures = append(result.Failures, ProviderError{Provider: provider.Name(), Err: err})
+			continue
+		}
+		*track = candidate
+		result.Provider = provider.Name()
+		return result, nil
+	}
+	return nil, &ChainError{Failures: result.Failures}
+}
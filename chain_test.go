@@ -0,0 +1,239 @@
+package golyrics
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestChain_FetchResult(t *testing.T) {
+	blocked := errors.New("blocked")
+	tests := []struct {
+		name       string
+		providers  []Provider
+		want       *Result
+		wantLyrics string
+		wantErr    bool
+	}{
+		{
+			name: "should answer from the first provider with lyrics",
+			providers: []Provider{
+				&staticProvider{name: "first", lyrics: "one"},
+				&staticProvider{name: "second", lyrics: "two"},
+			},
+			want:       &Result{Provider: "first"},
+			wantLyrics: "one",
+		},
+		{
+			name: "should fall through failing and empty providers",
+			providers: []Provider{
+				&staticProvider{name: "failing", err: blocked},
+				&staticProvider{name: "empty"},
+				&staticProvider{name: "last", lyrics: "three"},
+			},
+			want: &Result{
+				Provider: "last",
+				Failures: []ProviderError{
+					{Provider: "failing", Err: blocked},
+					{Provider: "empty", Err: ErrNotFound},
+				},
+			},
+			wantLyrics: "three",
+		},
+		{
+			name: "should fail when every provider fails",
+			providers: []Provider{
+				&staticProvider{name: "failing", err: blocked},
+			},
+			wantLyrics: "old",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		track := Track{Artist: "Blackfield", Name: "Pain", Lyrics: "old"}
+		got, err := NewChain(tt.providers...).FetchResult(context.Background(), &track)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. Chain.FetchResult() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. Chain.FetchResult() = %+v, want %+v", tt.name, got, tt.want)
+		}
+		if track.Lyrics != tt.wantLyrics {
+			t.Errorf("%q. Chain.FetchResult() lyrics = %q, want %q", tt.name, track.Lyrics, tt.wantLyrics)
+		}
+	}
+}
+
+// featuringProvider adds a featured artist to the tracks it fetches,
+// even when it fails.
+type featuringProvider struct {
+	staticProvider
+	featuring string
+}
+
+func (p *featuringProvider) Fetch(ctx context.Context, track *Track) error {
+	track.addFeaturing(p.featuring)
+	return p.staticProvider.Fetch(ctx, track)
+}
+
+func TestChain_FetchResultMetadata(t *testing.T) {
+	metadata := &TrackMetadata{Featuring: []string{"Aviv Geffen"}}
+	track := Track{Artist: "Blackfield", Name: "Pain", Metadata: metadata}
+	chain := NewChain(
+		&featuringProvider{staticProvider: staticProvider{name: "failing", err: ErrNotFound}, featuring: "Steven Wilson"},
+		&staticProvider{name: "last", lyrics: "Pain"},
+	)
+	if _, err := chain.FetchResult(context.Background(), &track); err != nil {
+		t.Fatalf("Chain.FetchResult() error = %v", err)
+	}
+	want := []string{"Aviv Geffen"}
+	if !reflect.DeepEqual(track.Metadata.Featuring, want) {
+		t.Errorf("Chain.FetchResult() featuring = %v, want %v", track.Metadata.Featuring, want)
+	}
+	if !reflect.DeepEqual(metadata.Featuring, want) {
+		t.Errorf("Chain.FetchResult() changed the metadata of the track to %v", metadata.Featuring)
+	}
+}
+
+func TestChain_FetchResultCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	chain := NewChain(
+		&staticProvider{name: "failing", err: context.Canceled},
+		&staticProvider{name: "never", lyrics: "never"},
+	)
+	track := Track{}
+	if _, err := chain.FetchResult(ctx, &track); err != context.Canceled {
+		t.Errorf("Chain.FetchResult() error = %v, want %v", err, context.Canceled)
+	}
+	if track.Lyrics != "" {
+		t.Errorf("Chain.FetchResult() used a provider after cancellation")
+	}
+}
+
+func TestChain_Search(t *testing.T) {
+	blocked := errors.New("blocked")
+	found := []Track{{Artist: "Blackfield", Name: "Pain"}}
+	tests := []struct {
+		name      string
+		providers []Provider
+		want      []Track
+		wantErr   bool
+	}{
+		{
+			name: "should return the first non-empty results",
+			providers: []Provider{
+				&staticProvider{name: "failing", err: blocked},
+				&staticProvider{name: "empty", tracks: []Track{}},
+				&staticProvider{name: "found", tracks: found},
+			},
+			want: found,
+		},
+		{
+			name: "should return no results when providers find nothing",
+			providers: []Provider{
+				&staticProvider{name: "failing", err: blocked},
+				&staticProvider{name: "empty", tracks: []Track{}},
+			},
+			want: []Track{},
+		},
+		{
+			name: "should fail when every provider fails",
+			providers: []Provider{
+				&staticProvider{name: "failing", err: blocked},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		got, err := NewChain(tt.providers...).Search(context.Background(), "blackfield:pain")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. Chain.Search() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. Chain.Search() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClient_FetchResult(t *testing.T) {
+	RegisterProvider("chain-test-empty", func(c *Client) Provider {
+		return &staticProvider{name: "chain-test-empty"}
+	})
+	defer unregisterProvider("chain-test-empty")
+	RegisterProvider("chain-test-found", func(c *Client) Provider {
+		return &staticProvider{name: "chain-test-found", lyrics: "found"}
+	})
+	defer unregisterProvider("chain-test-found")
+	client := NewClient(WithProviders("chain-test-empty", "missing", "chain-test-found"))
+
+	track := Track{}
+	got, err := client.FetchResult(context.Background(), &track)
+	if err != nil {
+		t.Fatalf("Client.FetchResult() error = %v", err)
+	}
+	if got.Provider != "chain-test-found" || len(got.Failures) != 2 || track.Lyrics != "found" {
+		t.Errorf("Client.FetchResult() = %+v with lyrics %q", got, track.Lyrics)
+	}
+}
+
+func TestChainError_Is(t *testing.T) {
+	blocked := errors.New("blocked")
+	tests := []struct {
+		name     string
+		failures []ProviderError
+		target   error
+		want     bool
+	}{
+		{
+			name:     "should match when every provider failed with the target",
+			failures: []ProviderError{{Provider: "first", Err: ErrNotFound}, {Provider: "second", Err: ErrStub}},
+			target:   ErrNotFound,
+			want:     true,
+		},
+		{
+			name:     "should not match when one provider failed otherwise",
+			failures: []ProviderError{{Provider: "first", Err: ErrNotFound}, {Provider: "second", Err: blocked}},
+			target:   ErrNotFound,
+		},
+		{
+			name:   "should not match without failures",
+			target: ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		if got := errors.Is(&ChainError{Failures: tt.failures}, tt.target); got != tt.want {
+			t.Errorf("%q. errors.Is(ChainError) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClient_NegativeCacheChain(t *testing.T) {
+	first := &countingProvider{staticProvider: staticProvider{name: "first"}}
+	second := &countingProvider{staticProvider: staticProvider{name: "second"}}
+	client := NewClient(WithProviderInstance(NewChain(first, second)), WithCache(NewMemoryCache(0, 0)))
+	for i := 0; i < 2; i++ {
+		if err := client.Fetch(&Track{Artist: "Nobody", Name: "Nothing"}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Client.Fetch() error = %v, want %v", err, ErrNotFound)
+		}
+	}
+	if first.fetches != 1 || second.fetches != 1 {
+		t.Errorf("Client.Fetch() reached the providers %d and %d times, want 1", first.fetches, second.fetches)
+	}
+}
+
+func TestClient_FetchKeepsMetadata(t *testing.T) {
+	metadata := &TrackMetadata{Featuring: []string{"Aviv Geffen"}}
+	track := Track{Artist: "Blackfield", Name: "Pain", Metadata: metadata}
+	provider := &featuringProvider{staticProvider: staticProvider{name: "failing", err: ErrNotFound}, featuring: "Steven Wilson"}
+	client := NewClient(WithProviderInstance(provider))
+	if err := client.Fetch(&track); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Client.Fetch() error = %v, want %v", err, ErrNotFound)
+	}
+	if want := []string{"Aviv Geffen"}; track.Metadata != metadata || !reflect.DeepEqual(metadata.Featuring, want) {
+		t.Errorf("Client.Fetch() changed the metadata of the track to %+v", track.Metadata)
+	}
+}
@@ -0,0 +1,45 @@
+package golyrics
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// instrumentalPattern matches lyrics that only say the track is an instrumental.
+	instrumentalPattern = regexp.MustCompile(`(?i)^\W*(?:this (?:song|track) is an? )?instrumental(?: track| song)?\W*$`)
+	// stubPattern matches the placeholder of the lyrics of new pages.
+	stubPattern = regexp.MustCompile(`(?i)put lyrics here`)
+)
+
+// classifyLyrics checks that lyrics are real lyrics rather than a placeholder.
+// licensed is false when the page holding them shows the licensing notice,
+// which can also be part of the lyrics themselves.
+// It returns ErrNotLicensed, a *TruncatedError, ErrInstrumental or ErrStub
+// for placeholders, and nil for real lyrics.
+func classifyLyrics(lyrics string, licensed bool) error {
+	var excerpt []string
+	for _, line := range strings.Split(lyrics, "\n") {
+		if strings.Contains(strings.ToLower(line), licensingNotice) {
+			licensed = false
+			continue
+		}
+		excerpt = append(excerpt, line)
+	}
+	if !licensed {
+		text := strings.TrimSpace(strings.Join(excerpt, "\n"))
+		if text == "" {
+			return ErrNotLicensed
+		}
+		return &TruncatedError{Excerpt: text}
+	}
+
+	text := strings.TrimSpace(lyrics)
+	switch {
+	case text == "", stubPattern.MatchString(text):
+		return ErrStub
+	case instrumentalPattern.MatchString(text):
+		return ErrInstrumental
+	}
+	return nil
+}
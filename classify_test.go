@@ -0,0 +1,88 @@
+package golyrics
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_classifyLyrics(t *testing.T) {
+	tests := []struct {
+		name        string
+		lyrics      string
+		licensed    bool
+		wantErr     error
+		wantExcerpt string
+	}{
+		{
+			name:     "should accept real lyrics",
+			lyrics:   "Pain\nCan't run\n\n(Instrumental)\nGo away",
+			licensed: true,
+		},
+		{
+			name:     "should accept lyrics mentioning instrumentals",
+			lyrics:   "Play me something instrumental",
+			licensed: true,
+		},
+		{
+			name:     "should fail as not licensed for licensing notices alone",
+			lyrics:   "Unfortunately, we are not licensed to display the full lyrics for this song at the moment.",
+			licensed: true,
+			wantErr:  ErrNotLicensed,
+		},
+		{
+			name:     "should fail as not licensed for empty lyrics of unlicensed pages",
+			lyrics:   "",
+			licensed: false,
+			wantErr:  ErrNotLicensed,
+		},
+		{
+			name:        "should fail as truncated for lyrics followed by a licensing notice",
+			lyrics:      "Pain\nCan't run\n\nUnfortunately, We are not licensed to display the full lyrics for this song at the moment.",
+			licensed:    true,
+			wantErr:     ErrTruncated,
+			wantExcerpt: "Pain\nCan't run",
+		},
+		{
+			name:        "should fail as truncated for excerpts of unlicensed pages",
+			lyrics:      "Pain\nCan't run",
+			licensed:    false,
+			wantErr:     ErrTruncated,
+			wantExcerpt: "Pain\nCan't run",
+		},
+		{
+			name:     "should fail as instrumental for instrumental markers",
+			lyrics:   "\n  [Instrumental]  \n",
+			licensed: true,
+			wantErr:  ErrInstrumental,
+		},
+		{
+			name:     "should fail as instrumental for sentences saying so",
+			lyrics:   "This song is an instrumental.",
+			licensed: true,
+			wantErr:  ErrInstrumental,
+		},
+		{
+			name:     "should fail as a stub for empty lyrics",
+			lyrics:   " \n\n ",
+			licensed: true,
+			wantErr:  ErrStub,
+		},
+		{
+			name:     "should fail as a stub for placeholders of new pages",
+			lyrics:   "PUT LYRICS HERE (DELETE THIS LINE)",
+			licensed: true,
+			wantErr:  ErrStub,
+		},
+	}
+	for _, tt := range tests {
+		err := classifyLyrics(tt.lyrics, tt.licensed)
+		if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+			t.Errorf("%q. classifyLyrics() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		var truncated *TruncatedError
+		if errors.As(err, &truncated) && truncated.Excerpt != tt.wantExcerpt {
+			t.Errorf("%q. classifyLyrics() excerpt = %q, want %q", tt.name, truncated.Excerpt, tt.wantExcerpt)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package golyrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Client searches for tracks and fetches their lyrics using a Provider.
+// Create one with NewClient; a Client is safe for concurrent use.
+type Client struct {
+	httpClient    *http.Client
+	searchBaseURI string
+	lyricsBaseURI string
+	apiURI        string
+	userAgent     string
+	maxBodySize   int64
+	retryPolicy   RetryPolicy
+	formatting    bool
+
+	defaultRateLimit RateLimit
+	rateLimits       map[string]RateLimit
+	rateLimiter      *rateLimiter
+
+	cache            Cache
+	cacheTTL         time.Duration
+	negativeCacheTTL time.Duration
+
+	revalidationWindow time.Duration
+
+	flights *flightGroup
+
+	providerNames []string
+	race          *RaceConfig
+	provider      Provider
+}
+
+// Option configures a Client. Options are applied in order,
+// so a later option overrides an earlier one.
+type Option func(*Client)
+
+// WithHTTPClient makes the Client send its requests using a copy of httpClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		copied := *httpClient
+		c.httpClient = &copied
+	}
+}
+
+// WithTransport sets the RoundTripper used for the Client's requests.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = transport
+	}
+}
+
+// WithTimeout sets the time limit for each request made by the Client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithMaxResponseSize limits the size of the response bodies read by the Client.
+// Larger responses fail with ErrTooLarge. The default is DefaultMaxResponseSize.
+func WithMaxResponseSize(size int64) Option {
+	return func(c *Client) {
+		c.maxBodySize = size
+	}
+}
+
+// WithSearchBaseURI sets the URI that the wikia provider appends search queries to.
+func WithSearchBaseURI(uri string) Option {
+	return func(c *Client) {
+		c.searchBaseURI = uri
+	}
+}
+
+// WithLyricsBaseURI sets the URI that the wikia provider appends page titles to.
+func WithLyricsBaseURI(uri string) Option {
+	return func(c *Client) {
+		c.lyricsBaseURI = uri
+	}
+}
+
+// WithAPIURI sets the api.php endpoint of the MediaWiki site
+// read by the mediawiki provider.
+func WithAPIURI(uri string) Option {
+	return func(c *Client) {
+		c.apiURI = uri
+	}
+}
+
+// WithProvider makes the Client use the provider registered under name.
+// The default is "wikia".
+func WithProvider(name string) Option {
+	return WithProviders(name)
+}
+
+// WithProviders makes the Client use the providers registered under names,
+// tried in the given order through a Chain.
+func WithProviders(names ...string) Option {
+	return func(c *Client) {
+		c.providerNames = names
+		c.race = nil
+		c.provider = nil
+	}
+}
+
+// WithRace makes the Client query the providers registered under names
+// concurrently through a Race configured by config.
+func WithRace(config RaceConfig, names ...string) Option {
+	return func(c *Client) {
+		c.providerNames = names
+		c.race = &config
+		c.provider = nil
+	}
+}
+
+// WithProviderInstance makes the Client use provider directly,
+// without looking it up in the registry.
+func WithProviderInstance(provider Provider) Option {
+	return func(c *Client) {
+		c.providerNames = []string{provider.Name()}
+		c.race = nil
+		c.provider = provider
+	}
+}
+
+// NewClient creates a Client configured with the given options.
+func NewClient(options ...Option) *Client {
+	c := &Client{
+		httpClient:    &http.Client{},
+		searchBaseURI: searchBaseURI,
+		lyricsBaseURI: lyricsBaseURI,
+		apiURI:        apiURI,
+		maxBodySize:   DefaultMaxResponseSize,
+		retryPolicy:   DefaultRetryPolicy,
+
+		cacheTTL:         DefaultCacheTTL,
+		negativeCacheTTL: DefaultNegativeCacheTTL,
+
+		revalidationWindow: DefaultRevalidationWindow,
+
+		providerNames: []string{wikiaProviderName},
+	}
+	for _, option := range options {
+		option(c)
+	}
+	if c.defaultRateLimit != (RateLimit{}) || len(c.rateLimits) > 0 {
+		c.rateLimiter = newRateLimiter(c.defaultRateLimit, c.rateLimits)
+	}
+	if c.provider == nil {
+		c.provider = c.newProvider()
+	}
+	c.flights = &flightGroup{}
+	return c
+}
+
+func (c *Client) newProvider() Provider {
+	if len(c.providerNames) == 1 && c.race == nil {
+		return newProvider(c.providerNames[0], c)
+	}
+	providers := make([]Provider, len(c.providerNames))
+	for i, name := range c.providerNames {
+		providers[i] = newProvider(name, c)
+	}
+	if c.race != nil {
+		return NewRace(*c.race, providers...)
+	}
+	return NewChain(providers...)
+}
+
+// DefaultClient is the Client used by the package-level functions.
+var DefaultClient = NewClient()
+
+// Provider returns the Provider used by the Client.
+func (c *Client) Provider() Provider {
+	return c.provider
+}
+
+// Search searches for tracks
+// using a string query that can be part of the track name or artist.
+func (c *Client) Search(query string) ([]Track, error) {
+	return c.SearchContext(context.Background(), query)
+}
+
+// SearchContext is like Search but uses ctx for the request and parsing.
+func (c *Client) SearchContext(ctx context.Context, query string) ([]Track, error) {
+	return c.search(ctx, query)
+}
+
+// SearchByArtistAndName searches for tracks
+// using artist and name of the track.
+func (c *Client) SearchByArtistAndName(artist, name string) ([]Track, error) {
+	return c.SearchByArtistAndNameContext(context.Background(), artist, name)
+}
+
+// SearchByArtistAndNameContext is like SearchByArtistAndName but uses ctx
+// for the request and parsing.
+func (c *Client) SearchByArtistAndNameContext(ctx context.Context, artist, name string) ([]Track, error) {
+	return c.SearchContext(ctx, artist+":"+name)
+}
+
+// Fetch fetches the lyrics of a Track and sets it on that track.
+func (c *Client) Fetch(track *Track) error {
+	return c.FetchContext(context.Background(), track)
+}
+
+// FetchContext is like Fetch but uses ctx for the request and parsing.
+// The track is left untouched if ctx is done before the lyrics are ready.
+func (c *Client) FetchContext(ctx context.Context, track *Track) error {
+	_, err := c.fetch(ctx, track)
+	return err
+}
+
+// FetchResult is like FetchContext but also reports
+// which provider answered and why the ones tried before it failed.
+func (c *Client) FetchResult(ctx context.Context, track *Track) (*Result, error) {
+	return c.fetch(ctx, track)
+}
@@ -0,0 +1,315 @@
+package golyrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "golyrics-test" {
+			t.Errorf("search request User-Agent = %q, want %q", r.Header.Get("User-Agent"), "golyrics-test")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("query") {
+		case "broken":
+			fmt.Fprint(w, `<html>Oops</html>`)
+		case "busy":
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "blackfield:pain":
+			fmt.Fprint(w, `{"suggestions":["Blackfield:Pain","Blackfield","Blackfield:Painkiller"]}`)
+		default:
+			fmt.Fprint(w, `{"suggestions":[]}`)
+		}
+	})
+	mux.HandleFunc("/slow/", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	mux.HandleFunc("/wiki/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch r.URL.Path {
+		case "/wiki/Missing:Page":
+			http.NotFound(w, r)
+		case "/wiki/Broken:Server":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/wiki/Licensed:Song":
+			fmt.Fprint(w, `<html><body><div class='lyricbox'>Unfortunately, we are not licensed to display the full lyrics for this song at the moment.</div></body></html>`)
+		case "/wiki/Truncated:Song":
+			fmt.Fprint(w, `<html><body><div class='lyricbox'>Pain<br/>Can&#39;t run<br/><br/>Unfortunately, we are not licensed to display the full lyrics for this song at the moment.</div></body></html>`)
+		case "/wiki/Instrumental:Song":
+			fmt.Fprint(w, `<html><body><div class='lyricbox'><b>Instrumental</b></div></body></html>`)
+		case "/wiki/Stub:Song":
+			fmt.Fprint(w, `<html><body><div class='lyricbox'> <div class='lyricsbreak'></div></div></body></html>`)
+		case "/wiki/Blackfield:Pain":
+			fmt.Fprint(w, `<html><body><div class='lyricbox'>Pain<br/>Can&#39;t run<div class='lyricsbreak'></div></div></body></html>`)
+		default:
+			fmt.Fprint(w, `<html><body><p>Nothing here</p></body></html>`)
+		}
+	})
+	return httptest.NewServer(mux)
+}
+
+func newTestClient(server *httptest.Server, options ...Option) *Client {
+	options = append([]Option{
+		WithSearchBaseURI(server.URL + "/search?query="),
+		WithLyricsBaseURI(server.URL + "/wiki/"),
+		WithUserAgent("golyrics-test"),
+		WithRetryPolicy(RetryPolicy{}),
+	}, options...)
+	return NewClient(options...)
+}
+
+func TestNewClient(t *testing.T) {
+	httpClient := &http.Client{Timeout: time.Minute}
+	transport := &http.Transport{}
+	tests := []struct {
+		name    string
+		options []Option
+		want    Client
+	}{
+		{
+			name: "should use package defaults without options",
+			want: Client{
+				httpClient:    &http.Client{},
+				searchBaseURI: searchBaseURI,
+				lyricsBaseURI: lyricsBaseURI,
+				apiURI:        apiURI,
+				maxBodySize:   DefaultMaxResponseSize,
+				retryPolicy:   DefaultRetryPolicy,
+
+				cacheTTL:         DefaultCacheTTL,
+				negativeCacheTTL: DefaultNegativeCacheTTL,
+
+				revalidationWindow: DefaultRevalidationWindow,
+
+				providerNames: []string{"wikia"},
+			},
+		},
+		{
+			name: "should apply options in order without touching the given http client",
+			options: []Option{
+				WithHTTPClient(httpClient),
+				WithTimeout(time.Second),
+				WithTransport(transport),
+				WithUserAgent("agent"),
+				WithSearchBaseURI("http://search/"),
+				WithLyricsBaseURI("http://lyrics/"),
+				WithAPIURI("http://wiki/api.php"),
+				WithMaxResponseSize(1024),
+				WithRetryPolicy(RetryPolicy{MaxAttempts: 5}),
+				WithCacheTTL(time.Hour, -1),
+				WithFormatting(true),
+			},
+			want: Client{
+				httpClient:    &http.Client{Timeout: time.Second, Transport: transport},
+				searchBaseURI: "http://search/",
+				lyricsBaseURI: "http://lyrics/",
+				apiURI:        "http://wiki/api.php",
+				userAgent:     "agent",
+				maxBodySize:   1024,
+				retryPolicy:   RetryPolicy{MaxAttempts: 5},
+				formatting:    true,
+
+				cacheTTL:         time.Hour,
+				negativeCacheTTL: -1,
+
+				revalidationWindow: DefaultRevalidationWindow,
+
+				providerNames: []string{"wikia"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got := NewClient(tt.options...)
+		if got.Provider().Name() != "wikia" {
+			t.Errorf("%q. NewClient() provider = %v, want %v", tt.name, got.Provider().Name(), "wikia")
+		}
+		got.provider = nil
+		got.flights = nil
+		if !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("%q. NewClient() = %+v, want %+v", tt.name, *got, tt.want)
+		}
+	}
+	if httpClient.Timeout != time.Minute || httpClient.Transport != nil {
+		t.Errorf("NewClient() modified the http client passed to WithHTTPClient")
+	}
+}
+
+func TestClient_Search(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	client := newTestClient(server)
+
+	tests := []struct {
+		name    string
+		query   string
+		want    []Track
+		wantErr error
+	}{
+		{
+			name:  "should return the suggestions that look like tracks",
+			query: "blackfield:pain",
+			want: []Track{
+				{Artist: "Blackfield", Name: "Pain"},
+				{Artist: "Blackfield", Name: "Painkiller"},
+			},
+		},
+		{
+			name:  "should return no results when there are no suggestions",
+			query: "nothing",
+			want:  []Track{},
+		},
+		{
+			name:    "should fail with a parse error for malformed responses",
+			query:   "broken",
+			wantErr: ErrParse,
+		},
+		{
+			name:    "should fail when rate limited",
+			query:   "busy",
+			wantErr: ErrRateLimited,
+		},
+	}
+	for _, tt := range tests {
+		got, err := client.Search(tt.query)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%q. Client.Search() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. Client.Search() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClient_Fetch(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	client := newTestClient(server)
+
+	tests := []struct {
+		name       string
+		track      Track
+		wantLyrics string
+		wantErr    error
+	}{
+		{
+			name:       "should fetch and format the lyrics of the track",
+			track:      Track{Artist: "Blackfield", Name: "Pain"},
+			wantLyrics: "Pain\nCan't run",
+		},
+		{
+			name:       "should fetch the page of tracks with spaces and lowercase letters",
+			track:      Track{Artist: "blackfield", Name: " pain"},
+			wantLyrics: "Pain\nCan't run",
+		},
+		{
+			name:       "should fail as not found when the page has no lyrics box",
+			track:      Track{Artist: "Nobody", Name: "Nothing", Lyrics: "old"},
+			wantLyrics: "old",
+			wantErr:    ErrNotFound,
+		},
+		{
+			name:    "should fail as not found when the page does not exist",
+			track:   Track{Artist: "Missing", Name: "Page"},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "should fail as upstream error on 5xx statuses",
+			track:   Track{Artist: "Broken", Name: "Server"},
+			wantErr: ErrUpstream,
+		},
+		{
+			name:    "should fail as not licensed for licensing notices",
+			track:   Track{Artist: "Licensed", Name: "Song"},
+			wantErr: ErrNotLicensed,
+		},
+		{
+			name:    "should fail as truncated for lyrics cut short by a licensing notice",
+			track:   Track{Artist: "Truncated", Name: "Song"},
+			wantErr: ErrTruncated,
+		},
+		{
+			name:    "should fail as instrumental for instrumental markers",
+			track:   Track{Artist: "Instrumental", Name: "Song"},
+			wantErr: ErrInstrumental,
+		},
+		{
+			name:    "should fail as a stub for empty lyrics boxes",
+			track:   Track{Artist: "Stub", Name: "Song"},
+			wantErr: ErrStub,
+		},
+	}
+	for _, tt := range tests {
+		track := tt.track
+		if err := client.Fetch(&track); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%q. Client.Fetch() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if track.Lyrics != tt.wantLyrics {
+			t.Errorf("%q. Client.Fetch() lyrics = %q, want %q", tt.name, track.Lyrics, tt.wantLyrics)
+		}
+		if tt.wantErr == nil && (track.Provider != "wikia" || track.FetchedAt.IsZero()) {
+			t.Errorf("%q. Client.Fetch() provider = %q, fetched at %v, want wikia and the fetch time",
+				tt.name, track.Provider, track.FetchedAt)
+		}
+	}
+}
+
+func TestClient_Context(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	slowClient := newTestClient(server,
+		WithSearchBaseURI(server.URL+"/slow/?query="),
+		WithLyricsBaseURI(server.URL+"/slow/"),
+	)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		client  *Client
+		timeout time.Duration
+		ctx     context.Context
+		wantErr error
+	}{
+		{
+			name:    "should stop waiting for a slow host at the deadline",
+			client:  slowClient,
+			timeout: 50 * time.Millisecond,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:    "should not send requests with an already cancelled context",
+			client:  newTestClient(server),
+			ctx:     cancelled,
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		ctx := tt.ctx
+		if tt.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+		}
+		if _, err := tt.client.SearchContext(ctx, "blackfield:pain"); err != tt.wantErr {
+			t.Errorf("%q. Client.SearchContext() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		track := Track{Artist: "Blackfield", Name: "Pain", Lyrics: "old"}
+		if err := tt.client.FetchContext(ctx, &track); err != tt.wantErr {
+			t.Errorf("%q. Client.FetchContext() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if track.Lyrics != "old" {
+			t.Errorf("%q. Client.FetchContext() changed lyrics to %q", tt.name, track.Lyrics)
+		}
+	}
+}
@@ -0,0 +1,408 @@
+// Package diskcache implements a golyrics.Cache storing values on disk,
+// so that lyrics survive restarts and can be shared between processes.
+//
+// Each value is kept in its own file, named after the SHA-256 of its key.
+// Files are written to a temporary name and renamed into place,
+// so concurrent readers and writers, even in other processes,
+// never see partially written values, and expired or evicted values
+// are only removed if no other process stored a new one in their place.
+package diskcache
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tempPrefix starts the names of files being written.
+const tempPrefix = ".tmp-"
+
+// staleTempAge is the age after which a temporary file is assumed to be
+// left over by a crashed writer and is removed during eviction.
+const staleTempAge = time.Hour
+
+// lowWater is the share of maxBytes evictions free the cache down to,
+// so that the next writes do not evict again at once.
+const lowWater = 0.9
+
+// Cache is a golyrics.Cache storing values in files under a directory.
+type Cache struct {
+	dir      string
+	maxBytes int64
+	now      func() time.Time
+
+	// mu guards size and keeps evictions of this process
+	// from running concurrently.
+	mu sync.Mutex
+	// size estimates the total size of the files, or is -1 until they
+	// are first measured. Files written by other processes are only
+	// counted when evicting, which measures them again.
+	size int64
+}
+
+// Entry describes a value stored in a Cache.
+type Entry struct {
+	Key string
+	// Size is the size of the file holding the value.
+	Size int64
+	// Expires is when the value expires, or the zero time if it never does.
+	Expires time.Time
+	// Accessed is when the value was last stored or read.
+	Accessed time.Time
+}
+
+// header is the first line of the file holding a value.
+type header struct {
+	Key     string `json:"key"`
+	Expires int64  `json:"expires,omitempty"`
+}
+
+// New creates a Cache storing values under dir, creating it if needed.
+// Once the files take more than maxBytes together, the expired and then
+// the least recently used ones are removed, until they take at most
+// 90% of it. A maxBytes of zero or less means no limit.
+//
+// Only the files the Cache names after keys are considered,
+// other files under dir are left alone.
+func New(dir string, maxBytes int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Cache{dir: dir, maxBytes: maxBytes, now: time.Now, size: -1}, nil
+}
+
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, name[:2], name)
+}
+
+// Get returns the value stored under key, if it did not expire.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	path := c.path(key)
+	data, info, err := readFile(path)
+	if err != nil {
+		return nil, false
+	}
+	h, value, err := decode(data)
+	if err != nil || h.Key != key {
+		return nil, false
+	}
+	now := c.now()
+	if h.expired(now) {
+		removeUnchanged(path, info)
+		return nil, false
+	}
+	os.Chtimes(path, now, now)
+	return value, true
+}
+
+// Set stores value under key for ttl. A ttl of zero or less never expires.
+// Errors are ignored, as a cache that cannot be written to is only slower.
+func (c *Cache) Set(key string, value []byte, ttl time.Duration) {
+	now := c.now()
+	h := header{Key: key}
+	if ttl > 0 {
+		h.Expires = now.Add(ttl).UnixNano()
+	}
+	path := c.path(key)
+	var previous int64
+	if info, err := os.Stat(path); err == nil {
+		previous = info.Size()
+	}
+	if err := c.write(path, h, value); err != nil {
+		return
+	}
+	os.Chtimes(path, now, now)
+	if c.maxBytes > 0 {
+		if info, err := os.Stat(path); err == nil {
+			c.grow(info.Size() - previous)
+		}
+	}
+}
+
+// grow adds delta to the estimated size of the cache,
+// and evicts values once it is over maxBytes.
+func (c *Cache) grow(delta int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size >= 0 {
+		c.size += delta
+	}
+	if c.size < 0 || c.size > c.maxBytes {
+		c.size = c.evict()
+	}
+}
+
+// Delete removes the value stored under key, if any.
+func (c *Cache) Delete(key string) {
+	os.Remove(c.path(key))
+}
+
+func (c *Cache) write(path string, h header, value []byte) error {
+	encoded, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile(filepath.Dir(path), tempPrefix)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	writer.Write(encoded)
+	writer.WriteByte('\n')
+	writer.Write(value)
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return nil
+}
+
+// readFile returns the content of the file at path,
+// and its FileInfo as it was when read.
+func readFile(path string) ([]byte, os.FileInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := ioutil.ReadAll(f)
+	return data, info, err
+}
+
+// removeUnchanged removes the file at path, unless another process
+// wrote a new value in its place since info was taken.
+// It reports whether the file is gone.
+func removeUnchanged(path string, info os.FileInfo) (bool, error) {
+	current, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !os.SameFile(current, info) || !current.ModTime().Equal(info.ModTime()) || current.Size() != info.Size() {
+		return false, nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	return true, nil
+}
+
+func decode(data []byte) (header, []byte, error) {
+	var h header
+	newline := bytes.IndexByte(data, '\n')
+	if newline < 0 {
+		return h, nil, errors.New("diskcache: missing header")
+	}
+	if err := json.Unmarshal(data[:newline], &h); err != nil {
+		return h, nil, err
+	}
+	return h, data[newline+1:], nil
+}
+
+func (h header) expired(now time.Time) bool {
+	return h.Expires != 0 && now.UnixNano() >= h.Expires
+}
+
+type file struct {
+	path string
+	info os.FileInfo
+}
+
+// files returns the value files of the cache, and removes temporary files
+// left over for longer than staleTempAge. Only the files laid out like path
+// names them, in a directory named after the first two hex digits of their
+// 64 hex digit name, are returned.
+func (c *Cache) files() ([]file, error) {
+	var files []file
+	now := c.now()
+	err := filepath.Walk(c.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if path == c.dir {
+			return nil
+		}
+		parent := filepath.Base(filepath.Dir(path))
+		if info.IsDir() {
+			if filepath.Dir(path) != c.dir || !isHex(info.Name(), 2) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Dir(filepath.Dir(path)) != c.dir {
+			return nil
+		}
+		if strings.HasPrefix(info.Name(), tempPrefix) {
+			if now.Sub(info.ModTime()) > staleTempAge {
+				os.Remove(path)
+			}
+			return nil
+		}
+		if isHex(info.Name(), sha256.Size*2) && strings.HasPrefix(info.Name(), parent) {
+			files = append(files, file{path: path, info: info})
+		}
+		return nil
+	})
+	return files, err
+}
+
+// isHex reports whether s is n lowercase hex digits.
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Entries returns the values stored in the cache,
+// the least recently used first, including expired ones.
+func (c *Cache) Entries() ([]Entry, error) {
+	files, err := c.files()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(files))
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f.path)
+		if err != nil {
+			continue
+		}
+		h, _, err := decode(data)
+		if err != nil {
+			continue
+		}
+		entry := Entry{Key: h.Key, Size: f.info.Size(), Accessed: f.info.ModTime()}
+		if h.Expires != 0 {
+			entry.Expires = time.Unix(0, h.Expires)
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Accessed.Before(entries[j].Accessed)
+	})
+	return entries, nil
+}
+
+// Purge removes every value from the cache.
+func (c *Cache) Purge() error {
+	return c.purge(func(path string, info os.FileInfo) bool {
+		return true
+	})
+}
+
+// PurgeExpired removes the expired values from the cache.
+func (c *Cache) PurgeExpired() error {
+	now := c.now()
+	return c.purge(func(path string, info os.FileInfo) bool {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return false
+		}
+		h, _, err := decode(data)
+		return err != nil || h.expired(now)
+	})
+}
+
+func (c *Cache) purge(match func(path string, info os.FileInfo) bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.size = -1
+	files, err := c.files()
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if !match(f.path, f.info) {
+			continue
+		}
+		if _, err := removeUnchanged(f.path, f.info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evict measures the files and, when they take more than maxBytes,
+// removes expired values, then the least recently used ones, until they
+// take at most lowWater of it. It returns the size left, or -1 if the
+// files could not be listed. c.mu must be held.
+func (c *Cache) evict() int64 {
+	files, err := c.files()
+	if err != nil {
+		return -1
+	}
+	var total int64
+	for _, f := range files {
+		total += f.info.Size()
+	}
+	if total <= c.maxBytes {
+		return total
+	}
+	target := int64(float64(c.maxBytes) * lowWater)
+
+	now := c.now()
+	live := files[:0]
+	for _, f := range files {
+		data, info, err := readFile(f.path)
+		if err != nil {
+			info = f.info
+		} else if h, _, err := decode(data); err == nil && !h.expired(now) {
+			live = append(live, file{path: f.path, info: info})
+			continue
+		}
+		if removed, _ := removeUnchanged(f.path, info); removed {
+			total -= f.info.Size()
+		}
+	}
+
+	sort.Slice(live, func(i, j int) bool {
+		return live[i].info.ModTime().Before(live[j].info.ModTime())
+	})
+	for _, f := range live {
+		if total <= target {
+			break
+		}
+		if removed, _ := removeUnchanged(f.path, f.info); removed {
+			total -= f.info.Size()
+		}
+	}
+	return total
+}
@@ -0,0 +1,243 @@
+package diskcache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mamal72/golyrics"
+)
+
+var _ golyrics.Cache = (*Cache)(nil)
+
+func newTestCache(t *testing.T, maxBytes int64) (*Cache, *time.Time) {
+	dir, err := ioutil.TempDir("", "diskcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := New(dir, maxBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	return cache, &now
+}
+
+func TestCache_GetSet(t *testing.T) {
+	cache, now := newTestCache(t, 0)
+	defer os.RemoveAll(cache.dir)
+	cache.Set("lyrics", []byte("Pain\nCan't run"), time.Minute)
+	cache.Set("forever", []byte{}, 0)
+
+	tests := []struct {
+		name    string
+		key     string
+		advance time.Duration
+		want    string
+		wantOK  bool
+	}{
+		{"should return stored values", "lyrics", 0, "Pain\nCan't run", true},
+		{"should return empty values", "forever", 0, "", true},
+		{"should miss unknown keys", "unknown", 0, "", false},
+		{"should miss expired values", "lyrics", time.Minute, "", false},
+		{"should keep values without expiry", "forever", 24 * time.Hour, "", true},
+	}
+	for _, tt := range tests {
+		*now = now.Add(tt.advance)
+		got, ok := cache.Get(tt.key)
+		if string(got) != tt.want || ok != tt.wantOK {
+			t.Errorf("%q. Cache.Get() = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	cache.Delete("forever")
+	if _, ok := cache.Get("forever"); ok {
+		t.Errorf("Cache.Get() returned a deleted value")
+	}
+}
+
+func TestCache_SurvivesReopening(t *testing.T) {
+	cache, _ := newTestCache(t, 0)
+	defer os.RemoveAll(cache.dir)
+	cache.Set("lyrics", []byte("Pain"), time.Hour)
+
+	reopened, err := New(cache.dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := reopened.Get("lyrics"); !ok || string(got) != "Pain" {
+		t.Errorf("Cache.Get() after reopening = %q, %v, want %q, true", got, ok, "Pain")
+	}
+}
+
+func TestCache_Evict(t *testing.T) {
+	cache, now := newTestCache(t, 0)
+	defer os.RemoveAll(cache.dir)
+	value := make([]byte, 100)
+	cache.Set("expired", value, time.Second)
+	*now = now.Add(time.Minute)
+	for _, key := range []string{"old", "used", "new"} {
+		cache.Set(key, value, 0)
+		*now = now.Add(time.Minute)
+	}
+	cache.Get("used")
+	*now = now.Add(time.Minute)
+
+	entries, err := cache.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.maxBytes = 4 * entries[len(entries)-1].Size
+	cache.Set("newest", value, 0)
+
+	for key, want := range map[string]bool{"expired": false, "old": false, "new": true, "used": true, "newest": true} {
+		if _, err := os.Stat(cache.path(key)); (err == nil) != want {
+			t.Errorf("Cache kept %q = %v after eviction, want %v", key, err == nil, want)
+		}
+	}
+
+	entries, err = cache.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+	if cache.size != total {
+		t.Errorf("Cache.size = %v after eviction, want %v", cache.size, total)
+	}
+	cache.Set("newest", value, 0)
+	if cache.size != total {
+		t.Errorf("Cache.size = %v after replacing a value, want %v", cache.size, total)
+	}
+}
+
+func TestCache_KeepsOtherFiles(t *testing.T) {
+	cache, _ := newTestCache(t, 1)
+	defer os.RemoveAll(cache.dir)
+	others := []string{
+		filepath.Join(cache.dir, "notes.txt"),
+		filepath.Join(cache.dir, "ab", "notes.txt"),
+		filepath.Join(cache.dir, "config", "settings.json"),
+	}
+	for _, path := range others {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte("keep me"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cache.Set("lyrics", []byte("Pain"), 0)
+	if err := cache.PurgeExpired(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Purge(); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range others {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Cache removed %q, which it did not write", path)
+		}
+	}
+}
+
+func TestCache_KeepsReplacedValues(t *testing.T) {
+	cache, now := newTestCache(t, 0)
+	defer os.RemoveAll(cache.dir)
+	cache.Set("lyrics", []byte("old"), time.Minute)
+	path := cache.path("lyrics")
+	_, expired, err := readFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	*now = now.Add(time.Hour)
+	cache.Set("lyrics", []byte("new"), time.Minute)
+	if removed, err := removeUnchanged(path, expired); removed || err != nil {
+		t.Errorf("removeUnchanged() = %v, %v for a replaced value, want false, nil", removed, err)
+	}
+	if got, ok := cache.Get("lyrics"); !ok || string(got) != "new" {
+		t.Errorf("Cache.Get() = %q, %v, want %q, true", got, ok, "new")
+	}
+
+	_, current, err := readFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed, err := removeUnchanged(path, current); !removed || err != nil {
+		t.Errorf("removeUnchanged() = %v, %v for an unchanged value, want true, nil", removed, err)
+	}
+}
+
+func TestCache_EntriesAndPurge(t *testing.T) {
+	cache, now := newTestCache(t, 0)
+	defer os.RemoveAll(cache.dir)
+	cache.Set("expiring", []byte("a"), time.Minute)
+	cache.Set("forever", []byte("b"), 0)
+
+	entries, err := cache.Entries()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("Cache.Entries() = %v, %v, want 2 entries", entries, err)
+	}
+	for _, entry := range entries {
+		wantExpires := entry.Key == "expiring"
+		if !entry.Expires.IsZero() != wantExpires || entry.Size == 0 {
+			t.Errorf("Cache.Entries() entry = %+v", entry)
+		}
+	}
+
+	*now = now.Add(time.Hour)
+	if err := cache.PurgeExpired(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := cache.Entries(); len(entries) != 1 || entries[0].Key != "forever" {
+		t.Errorf("Cache.PurgeExpired() left %v", entries)
+	}
+	if err := cache.Purge(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := cache.Entries(); len(entries) != 0 {
+		t.Errorf("Cache.Purge() left %v", entries)
+	}
+}
+
+func TestCache_ConcurrentWriters(t *testing.T) {
+	cache, _ := newTestCache(t, 0)
+	defer os.RemoveAll(cache.dir)
+	other, err := New(cache.dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]bool{"first value": true, "second, longer value": true}
+
+	var wg sync.WaitGroup
+	for i, c := range []*Cache{cache, other, cache, other} {
+		wg.Add(1)
+		go func(i int, c *Cache) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if i%2 == 0 {
+					c.Set("shared", []byte("first value"), 0)
+				} else {
+					c.Set("shared", []byte("second, longer value"), 0)
+				}
+				if got, ok := c.Get("shared"); ok && !values[string(got)] {
+					t.Errorf("Cache.Get() = %q, a partially written value", got)
+				}
+			}
+		}(i, c)
+	}
+	wg.Wait()
+
+	temps, _ := filepath.Glob(filepath.Join(cache.dir, "*", tempPrefix+"*"))
+	if len(temps) != 0 {
+		t.Errorf("Cache left temporary files %v", temps)
+	}
+}
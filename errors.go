@@ -0,0 +1,169 @@
+package golyrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when a provider has no lyrics for a track.
+	ErrNotFound = errors.New("golyrics: lyrics not found")
+	// ErrRateLimited is returned when a lyrics host asks to slow down.
+	// Use errors.As with a *StatusError to get how long to wait.
+	ErrRateLimited = errors.New("golyrics: rate limited")
+	// ErrNotLicensed is returned when a lyrics host only has a notice
+	// saying it is not licensed to display the lyrics.
+	ErrNotLicensed = errors.New("golyrics: lyrics not licensed")
+	// ErrUpstream is returned when a lyrics host fails with a 5xx status.
+	ErrUpstream = errors.New("golyrics: upstream server error")
+	// ErrParse is returned when a response cannot be understood.
+	ErrParse = errors.New("golyrics: unparseable response")
+	// ErrNotModified is returned by Client.Do when a conditional request
+	// made to revalidate cached lyrics found them unchanged.
+	ErrNotModified = errors.New("golyrics: not modified")
+	// ErrTooLarge is returned when a response is larger than the Client allows.
+	ErrTooLarge = errors.New("golyrics: response too large")
+	// ErrAmbiguous is returned when a track names a disambiguation page.
+	// Use errors.As with an *AmbiguousError to get the candidate tracks.
+	ErrAmbiguous = errors.New("golyrics: ambiguous track")
+	// ErrTooManyRedirects is returned when the redirects of a page
+	// loop or are too many to follow.
+	ErrTooManyRedirects = errors.New("golyrics: too many redirects")
+	// ErrInstrumental is returned when a page says its track is
+	// an instrumental, which has no lyrics.
+	ErrInstrumental = errors.New("golyrics: track is instrumental")
+	// ErrTruncated is returned when a page only shows part of the lyrics.
+	// Use errors.As with a *TruncatedError to get that part.
+	ErrTruncated = errors.New("golyrics: lyrics truncated")
+	// ErrStub is returned when the page of a track exists but only holds
+	// placeholder lyrics. It also matches ErrNotFound with errors.Is.
+	ErrStub error = stubError{}
+)
+
+type stubError struct{}
+
+func (stubError) Error() string {
+	return "golyrics: lyrics page is a stub"
+}
+
+// Is reports whether target is ErrNotFound, as stubs have no lyrics either.
+func (stubError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// TruncatedError is returned when a page only shows the beginning of the
+// lyrics, followed by a notice saying it is not licensed to show the rest.
+// It matches ErrTruncated and ErrNotLicensed with errors.Is.
+type TruncatedError struct {
+	// Excerpt is the part of the lyrics shown by the page.
+	Excerpt string
+}
+
+func (e *TruncatedError) Error() string {
+	return "golyrics: lyrics truncated, they are not licensed to be shown in full"
+}
+
+// Is reports whether target is ErrTruncated or ErrNotLicensed.
+func (e *TruncatedError) Is(target error) bool {
+	return target == ErrTruncated || target == ErrNotLicensed
+}
+
+// StatusError is returned when a lyrics host responds with an unsuccessful
+// HTTP status. It matches ErrNotFound, ErrRateLimited or ErrUpstream
+// with errors.Is depending on the status.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	// RetryAfter is how long the host asked to wait before retrying,
+	// or zero if it did not say.
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("golyrics: %s responded with %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Is reports whether the status of e means target.
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests ||
+			e.StatusCode == http.StatusServiceUnavailable && e.RetryAfter > 0
+	case ErrUpstream:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// ParseError is returned when a response from URL cannot be understood.
+// It matches ErrParse with errors.Is.
+type ParseError struct {
+	URL string
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("golyrics: cannot parse %s: %v", e.URL, e.Err)
+}
+
+// Unwrap returns the underlying parse error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrParse.
+func (e *ParseError) Is(target error) bool {
+	return target == ErrParse
+}
+
+// AmbiguousError is returned when the page of a track lists several songs
+// it could mean instead of lyrics. It matches ErrAmbiguous with errors.Is.
+type AmbiguousError struct {
+	Title string
+	// Candidates are the tracks listed by the page.
+	Candidates []Track
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("golyrics: %s is ambiguous between %d tracks", e.Title, len(e.Candidates))
+}
+
+// Is reports whether target is ErrAmbiguous.
+func (e *AmbiguousError) Is(target error) bool {
+	return target == ErrAmbiguous
+}
+
+// checkStatus returns a *StatusError if response is not successful.
+func checkStatus(response *http.Response) error {
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+	return &StatusError{
+		URL:        response.Request.URL.String(),
+		StatusCode: response.StatusCode,
+		RetryAfter: parseRetryAfter(response.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter parses a Retry-After header holding either
+// a number of seconds or an HTTP date, relative to now.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,124 @@
+package golyrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestStatusError_Is(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    *StatusError
+		target error
+		want   bool
+	}{
+		{
+			name:   "should match not found for 404",
+			err:    &StatusError{StatusCode: http.StatusNotFound},
+			target: ErrNotFound,
+			want:   true,
+		},
+		{
+			name:   "should match rate limited for 429",
+			err:    &StatusError{StatusCode: http.StatusTooManyRequests},
+			target: ErrRateLimited,
+			want:   true,
+		},
+		{
+			name:   "should match rate limited for 503 with Retry-After",
+			err:    &StatusError{StatusCode: http.StatusServiceUnavailable, RetryAfter: time.Second},
+			target: ErrRateLimited,
+			want:   true,
+		},
+		{
+			name:   "should not match rate limited for 503 without Retry-After",
+			err:    &StatusError{StatusCode: http.StatusServiceUnavailable},
+			target: ErrRateLimited,
+			want:   false,
+		},
+		{
+			name:   "should match upstream for 5xx",
+			err:    &StatusError{StatusCode: http.StatusBadGateway},
+			target: ErrUpstream,
+			want:   true,
+		},
+		{
+			name:   "should not match upstream for 4xx",
+			err:    &StatusError{StatusCode: http.StatusForbidden},
+			target: ErrUpstream,
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		wrapped := fmt.Errorf("lookup: %w", tt.err)
+		if got := errors.Is(wrapped, tt.target); got != tt.want {
+			t.Errorf("%q. errors.Is() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseError_Is(t *testing.T) {
+	cause := errors.New("unexpected end")
+	err := error(&ParseError{URL: "http://lyrics/", Err: cause})
+	if !errors.Is(err, ErrParse) || !errors.Is(err, cause) {
+		t.Errorf("errors.Is() does not match ErrParse and the cause for %v", err)
+	}
+}
+
+func TestAmbiguousError_Is(t *testing.T) {
+	err := error(&AmbiguousError{Title: "Blackfield:Pain", Candidates: []Track{{Artist: "Blackfield", Name: "Pain"}}})
+	if !errors.Is(err, ErrAmbiguous) || errors.Is(err, ErrNotFound) {
+		t.Errorf("errors.Is() does not match only ErrAmbiguous for %v", err)
+	}
+}
+
+func TestTruncatedError_Is(t *testing.T) {
+	err := error(&TruncatedError{Excerpt: "Pain"})
+	if !errors.Is(err, ErrTruncated) || !errors.Is(err, ErrNotLicensed) || errors.Is(err, ErrNotFound) {
+		t.Errorf("errors.Is() does not match only ErrTruncated and ErrNotLicensed for %v", err)
+	}
+}
+
+func TestErrStub_Is(t *testing.T) {
+	if !errors.Is(ErrStub, ErrNotFound) || errors.Is(ErrNotFound, ErrStub) {
+		t.Errorf("errors.Is() does not match ErrNotFound for %v", ErrStub)
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2018, 6, 30, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{
+			name:  "should parse seconds",
+			value: "120",
+			want:  2 * time.Minute,
+		},
+		{
+			name:  "should parse HTTP dates",
+			value: "Sat, 30 Jun 2018 12:00:30 GMT",
+			want:  30 * time.Second,
+		},
+		{
+			name:  "should ignore dates in the past",
+			value: "Sat, 30 Jun 2018 11:00:00 GMT",
+			want:  0,
+		},
+		{
+			name:  "should ignore garbage",
+			value: "soon",
+			want:  0,
+		},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("%q. parseRetryAfter() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package golyrics
+
+import (
+	"html"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// WithFormatting makes the Client keep the italic and bold text of lyrics,
+// marked with <i> and <b> tags. The rest of the lyrics is then escaped
+// like HTML text. By default, lyrics are plain text.
+func WithFormatting(formatting bool) Option {
+	return func(c *Client) {
+		c.formatting = formatting
+	}
+}
+
+// blockElements start and end a line of lyrics.
+var blockElements = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true, atom.Blockquote: true,
+	atom.Center: true, atom.Dd: true, atom.Div: true, atom.Dl: true, atom.Dt: true,
+	atom.Figure: true, atom.Footer: true, atom.H1: true, atom.H2: true, atom.H3: true,
+	atom.H4: true, atom.H5: true, atom.H6: true, atom.Header: true, atom.Hr: true,
+	atom.Li: true, atom.Ol: true, atom.P: true, atom.Pre: true, atom.Section: true,
+	atom.Table: true, atom.Tr: true, atom.Ul: true,
+}
+
+// skippedElements never hold lyrics.
+var skippedElements = map[atom.Atom]bool{
+	atom.Embed: true, atom.Iframe: true, atom.Ins: true, atom.Noscript: true,
+	atom.Object: true, atom.Script: true, atom.Style: true, atom.Template: true,
+}
+
+// adClasses mark the containers of ads placed inside lyrics.
+var adClasses = map[string]bool{
+	"ad": true, "ads": true, "adsbygoogle": true, "advert": true,
+	"advertisement": true, "rtMatcher": true, "sponsor": true,
+}
+
+// extractLyrics returns the text of the children of node:
+// every form of br and the boundaries of block elements become line breaks,
+// paragraphs are separated by blank lines, and scripts, styles, comments,
+// ads and hidden elements are dropped.
+// With formatting, italic and bold text is kept in <i> and <b> tags.
+func extractLyrics(node *xhtml.Node, formatting bool) string {
+	e := &extractor{formatting: formatting}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		e.walk(child)
+	}
+	return strings.Trim(e.text.String(), "\n")
+}
+
+type extractor struct {
+	formatting bool
+	text       strings.Builder
+	// newlines is the number of line breaks ending the text.
+	newlines int
+}
+
+func (e *extractor) walk(node *xhtml.Node) {
+	switch node.Type {
+	case xhtml.TextNode:
+		if isInterElementSpace(node) {
+			return
+		}
+		e.write(node.Data)
+		return
+	case xhtml.ElementNode:
+	default:
+		return
+	}
+
+	if skippedElements[node.DataAtom] || isAd(node) || isHidden(node) {
+		return
+	}
+	if node.DataAtom == atom.Br {
+		e.writeRaw("\n")
+		return
+	}
+	breaks := 0
+	if blockElements[node.DataAtom] {
+		breaks = 1
+	}
+	if node.DataAtom == atom.P {
+		breaks = 2
+	}
+	e.lineBreaks(breaks)
+	open, close := e.tags(node.DataAtom)
+	e.writeRaw(open)
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		e.walk(child)
+	}
+	e.writeRaw(close)
+	e.lineBreaks(breaks)
+}
+
+// tags returns the tags surrounding the text of an element
+// to keep its formatting, if any.
+func (e *extractor) tags(a atom.Atom) (open, close string) {
+	if !e.formatting {
+		return "", ""
+	}
+	switch a {
+	case atom.I, atom.Em:
+		return "<i>", "</i>"
+	case atom.B, atom.Strong:
+		return "<b>", "</b>"
+	}
+	return "", ""
+}
+
+// write adds text, escaped when the lyrics keep their formatting.
+func (e *extractor) write(text string) {
+	if e.formatting {
+		text = html.EscapeString(text)
+	}
+	e.writeRaw(text)
+}
+
+func (e *extractor) writeRaw(text string) {
+	if text == "" {
+		return
+	}
+	e.text.WriteString(text)
+	trimmed := strings.TrimRight(text, "\n")
+	if trimmed == "" {
+		e.newlines += len(text)
+	} else {
+		e.newlines = len(text) - len(trimmed)
+	}
+}
+
+// lineBreaks ends the text with at least n line breaks,
+// unless nothing was written yet.
+func (e *extractor) lineBreaks(n int) {
+	if e.text.Len() == 0 {
+		return
+	}
+	for e.newlines < n {
+		e.writeRaw("\n")
+	}
+}
+
+// isInterElementSpace reports whether node is only whitespace
+// next to a block element, used to lay out the HTML source.
+func isInterElementSpace(node *xhtml.Node) bool {
+	if strings.TrimSpace(node.Data) != "" {
+		return false
+	}
+	for _, sibling := range []*xhtml.Node{node.PrevSibling, node.NextSibling} {
+		if sibling != nil && sibling.Type == xhtml.ElementNode && blockElements[sibling.DataAtom] {
+			return true
+		}
+	}
+	return false
+}
+
+func isAd(node *xhtml.Node) bool {
+	for _, class := range strings.Fields(attr(node, "class")) {
+		if adClasses[class] {
+			return true
+		}
+	}
+	return false
+}
+
+func isHidden(node *xhtml.Node) bool {
+	if _, hidden := attrValue(node, "hidden"); hidden {
+		return true
+	}
+	style := strings.ToLower(strings.Replace(attr(node, "style"), " ", "", -1))
+	return strings.Contains(style, "display:none")
+}
+
+func attr(node *xhtml.Node, key string) string {
+	value, _ := attrValue(node, key)
+	return value
+}
+
+func attrValue(node *xhtml.Node, key string) (string, bool) {
+	for _, a := range node.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
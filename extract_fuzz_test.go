@@ -0,0 +1,66 @@
+//go:build go1.18
+// +build go1.18
+
+package golyrics
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// obfuscate encodes every rune of text as a character reference,
+// alternating decimal and hexadecimal ones, like the old lyrics boxes did.
+func obfuscate(text string) string {
+	var encoded strings.Builder
+	for i, r := range []rune(text) {
+		if i%2 == 0 {
+			fmt.Fprintf(&encoded, "&#%d;", r)
+		} else {
+			fmt.Fprintf(&encoded, "&#x%X;", r)
+		}
+	}
+	return encoded.String()
+}
+
+func FuzzExtractLyrics(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"Can't run from \"you\"",
+		"Salt & pepper\ncafé <3",
+		"<br/> &amp; &lt;br/&gt; &#x1F600;",
+		"<p>Мир</p><div>東京<script>x</script></div> مرحبا",
+		"&#128;&#0;&#xD800;&unknown",
+		"\n\nBlank\n\n",
+		"\xff\xfe",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		got := extractLyrics(parseLyricbox(text), false)
+		if utf8.ValidString(text) && !utf8.ValidString(got) {
+			t.Errorf("extractLyrics(%q) = %q, which is not valid UTF-8", text, got)
+		}
+
+		// Escaped text must come back as is, markup and entities included,
+		// except for what HTML parsers normalize and the surrounding newlines.
+		if !utf8.ValidString(text) || strings.ContainsAny(text, "\x00\r") {
+			return
+		}
+		want := strings.Trim(text, "\n")
+		if got := extractLyrics(parseLyricbox(html.EscapeString(text)), false); got != want {
+			t.Errorf("extractLyrics(html.EscapeString(%q)) = %q, want %q", text, got, want)
+		}
+
+		// So must text fully encoded as character references, except for
+		// the code points that HTML decodes as something else.
+		if strings.IndexFunc(text, func(r rune) bool { return r >= 0x80 && r <= 0x9F }) >= 0 {
+			return
+		}
+		if got := extractLyrics(parseLyricbox(obfuscate(text)), false); got != want {
+			t.Errorf("extractLyrics(obfuscate(%q)) = %q, want %q", text, got, want)
+		}
+	})
+}
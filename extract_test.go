@@ -0,0 +1,106 @@
+package golyrics
+
+import (
+	"strings"
+	"testing"
+
+	xhtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// parseLyricbox parses HTML into the children of a lyrics box.
+func parseLyricbox(HTML string) *xhtml.Node {
+	lyricbox := &xhtml.Node{Type: xhtml.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := xhtml.ParseFragment(strings.NewReader(HTML), lyricbox)
+	if err != nil {
+		panic(err)
+	}
+	for _, node := range nodes {
+		lyricbox.AppendChild(node)
+	}
+	return lyricbox
+}
+
+func Test_extractLyrics(t *testing.T) {
+	type args struct {
+		HTML       string
+		formatting bool
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "test should return a correct formatted string from HTML lyrics",
+			args: args{
+				HTML: "The shortest song in the universe<br/>Really isn't much fun<br/>It only has one puny verse<br/>. . . and then it's done!<div class='lyricsbreak'></div>\n",
+			},
+			want: "The shortest song in the universe\nReally isn't much fun\nIt only has one puny verse\n. . . and then it's done!",
+		},
+		{
+			name: "test should work for every spelling of breaks",
+			args: args{
+				HTML: "Hello<br>World<BR />Again<br/>And</br>Again",
+			},
+			want: "Hello\nWorld\nAgain\nAnd\nAgain",
+		},
+		{
+			name: "test should put block elements on their own lines",
+			args: args{
+				HTML: "<div>One</div>\n<div>Two</div>Three<ul><li>Four</li><li>Five</li></ul>",
+			},
+			want: "One\nTwo\nThree\nFour\nFive",
+		},
+		{
+			name: "test should separate paragraphs with blank lines",
+			args: args{
+				HTML: "<p>One<br>Two</p>\n<p>Three</p>",
+			},
+			want: "One\nTwo\n\nThree",
+		},
+		{
+			name: "test should drop scripts, styles, comments, ads and hidden text",
+			args: args{
+				HTML: "One<br><script>var lyrics = 'Fake';</script><!-- Two --><style>p { color: red }</style>" +
+					"<div class='rtMatcher'><span>Buy now</span></div><ins class='adsbygoogle'></ins>" +
+					"<span style='display: none'>Hidden</span>Two",
+			},
+			want: "One\nTwo",
+		},
+		{
+			name: "test should decode every kind of entity",
+			args: args{
+				HTML: "Salt &amp; pepper, caf&eacute; &#x41C;&#x438;&#x440;<br>&#67;&#97;&#110;&#39;&#116; run",
+			},
+			want: "Salt & pepper, café Мир\nCan't run",
+		},
+		{
+			name: "test should keep escaped markup as text",
+			args: args{
+				HTML: "&lt;br/&gt; is how you break lines",
+			},
+			want: "<br/> is how you break lines",
+		},
+		{
+			name: "test should drop formatting by default",
+			args: args{
+				HTML: "<i>Oh</i> <strong>yeah</strong> &amp; <u>more</u>",
+			},
+			want: "Oh yeah & more",
+		},
+		{
+			name: "test should keep italic and bold text with formatting",
+			args: args{
+				HTML:       "<i>Oh</i> <strong>yeah</strong> &amp; <u>more</u>",
+				formatting: true,
+			},
+			want: "<i>Oh</i> <b>yeah</b> &amp; more",
+		},
+	}
+	for _, tt := range tests {
+		if got := extractLyrics(parseLyricbox(tt.args.HTML), tt.args.formatting); got != tt.want {
+			t.Errorf("%q. extractLyrics() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
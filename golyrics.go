@@ -1,100 +1,99 @@
 package golyrics
 
 import (
-	"fmt"
-	"io/ioutil"
-	"net/http"
-	"net/url"
-	"regexp"
-	"strings"
-
-	"github.com/PuerkitoBio/goquery"
-	"github.com/buger/jsonparser"
+	"context"
+	"time"
 )
 
-const searchBaseURI = "http://lyrics.wikia.com/index.php?action=ajax&rs=getLinkSuggest&format=json&query="
-const lyricsBaseURI = "http://lyrics.wikia.com/wiki/"
-
-// Track is a music track containing Artist, Name and Lyrics.
+// Track is a music track containing Artist, Name and Lyrics,
+// along with the metadata the provider of the lyrics could find.
+// Metadata fields are left empty when unknown.
 type Track struct {
 	Artist string
 	Name   string
 	Lyrics string
-}
 
-// FetchLyrics fetches the lyrics of a Track and sets it on that track.
-func (track *Track) FetchLyrics() error {
-	URI := fmt.Sprintf("%s%s:%s", lyricsBaseURI, track.Artist, track.Name)
-	doc, err := goquery.NewDocument(URI)
-	if err != nil {
-		return err
-	}
-
-	lyricsHTML, err := doc.Find(".lyricbox").Html()
-	if err != nil {
-		return err
-	}
-
-	track.Lyrics = getFormattedLyrics(lyricsHTML)
-	return nil
+	Album       string
+	Year        int
+	TrackNumber int
+	Language    string
+	// Metadata holds the featured artists and writers of the track,
+	// or is nil when none are known. Keeping them behind a pointer
+	// lets tracks be compared with == and used as map keys.
+	Metadata *TrackMetadata
+	// URL is the canonical URL of the page the lyrics were found on.
+	URL string
+	// Provider is the name of the provider that fetched the lyrics.
+	Provider string
+	// FetchedAt is when the lyrics were fetched from the provider.
+	FetchedAt time.Time
+	// Synced holds the lyrics timed line by line, and maybe word by word,
+	// when they are known, such as for tracks read from LRC files.
+	Synced *SyncedLyrics
 }
 
-func breakToNewLine(HTML string) string {
-	return strings.Replace(HTML, "<br/>", "\n", -1)
+// TrackMetadata holds the metadata of a track that lists several names.
+type TrackMetadata struct {
+	Featuring []string
+	Writers   []string
 }
 
-func stripeHTMLTags(HTML string) string {
-	regex := regexp.MustCompile("<[^>]+>")
-	return regex.ReplaceAllString(HTML, "")
+// addFeaturing adds name to the featured artists of the track,
+// unless it is empty or already there.
+func (track *Track) addFeaturing(name string) {
+	if name == "" {
+		return
+	}
+	if track.Metadata == nil {
+		track.Metadata = &TrackMetadata{}
+	}
+	track.Metadata.Featuring = appendName(track.Metadata.Featuring, name)
 }
 
-func fixApostrophesAndQuotes(text string) string {
-	apostrophesFixed := strings.Replace(text, "&#39;", "'", -1)
-	return strings.Replace(apostrophesFixed, "&#34;", "\"", -1)
+// addWriter adds name to the writers of the track,
+// unless it is empty or already there.
+func (track *Track) addWriter(name string) {
+	if name == "" {
+		return
+	}
+	if track.Metadata == nil {
+		track.Metadata = &TrackMetadata{}
+	}
+	track.Metadata.Writers = appendName(track.Metadata.Writers, name)
 }
 
-func getSearchURI(query string) string {
-	return fmt.Sprintf("%s%s", searchBaseURI, url.QueryEscape(query))
+// FetchLyrics fetches the lyrics of a Track and sets it on that track.
+// It uses DefaultClient.
+func (track *Track) FetchLyrics() error {
+	return DefaultClient.Fetch(track)
 }
 
-func getFormattedLyrics(text string) string {
-	noBreaks := breakToNewLine(text)
-	noHTMLTags := stripeHTMLTags(noBreaks)
-	return fixApostrophesAndQuotes(noHTMLTags)
+// FetchLyricsContext is like FetchLyrics but uses ctx for the request.
+func (track *Track) FetchLyricsContext(ctx context.Context) error {
+	return DefaultClient.FetchContext(ctx, track)
 }
 
 // SearchTrack searches for tracks
 // using a string query that can be part of the track name or artist.
+// It uses DefaultClient.
 func SearchTrack(query string) ([]Track, error) {
-	requestURI := getSearchURI(query)
-	response, err := http.Get(requestURI)
-	if err != nil {
-		return nil, err
-	}
-	data, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	suggestions := []Track{}
-	jsonparser.ArrayEach(data, func(value []byte, _ jsonparser.ValueType, offset int, _ error) {
-		title := string(value)
-		trackParts := strings.SplitN(title, ":", 2)
-		if len(trackParts) < 2 {
-			return
-		}
-		track := Track{
-			Artist: trackParts[0],
-			Name:   trackParts[1],
-		}
-		suggestions = append(suggestions, track)
-	}, "suggestions")
+	return DefaultClient.Search(query)
+}
 
-	return suggestions, nil
+// SearchTrackContext is like SearchTrack but uses ctx for the request.
+func SearchTrackContext(ctx context.Context, query string) ([]Track, error) {
+	return DefaultClient.SearchContext(ctx, query)
 }
 
 // SearchTrackByArtistAndName searches for tracks
 // using artist and name of the track.
+// It uses DefaultClient.
 func SearchTrackByArtistAndName(artist, name string) ([]Track, error) {
-	return SearchTrack(artist + ":" + name)
+	return DefaultClient.SearchByArtistAndName(artist, name)
+}
+
+// SearchTrackByArtistAndNameContext is like SearchTrackByArtistAndName
+// but uses ctx for the request.
+func SearchTrackByArtistAndNameContext(ctx context.Context, artist, name string) ([]Track, error) {
+	return DefaultClient.SearchByArtistAndNameContext(ctx, artist, name)
 }
@@ -5,142 +5,6 @@ import (
 	"testing"
 )
 
-func Test_breakToNewLine(t *testing.T) {
-	type args struct {
-		HTML string
-	}
-	tests := []struct {
-		name string
-		args args
-		want string
-	}{
-		{
-			name: "test should work for breaks in the start of string",
-			args: args{
-				"<br/>Hello World",
-			},
-			want: "\nHello World",
-		},
-		{
-			name: "test should work for breaks in the middle of string",
-			args: args{
-				"Hello<br/>World",
-			},
-			want: "Hello\nWorld",
-		},
-		{
-			name: "test should work for breaks in the end of string",
-			args: args{
-				"Hello World<br/>",
-			},
-			want: "Hello World\n",
-		},
-	}
-	for _, tt := range tests {
-		if got := breakToNewLine(tt.args.HTML); got != tt.want {
-			t.Errorf("%q. breakToNewLine() = %v, want %v", tt.name, got, tt.want)
-		}
-	}
-}
-
-func Test_stripeHTMLTags(t *testing.T) {
-	type args struct {
-		HTML string
-	}
-	tests := []struct {
-		name string
-		args args
-		want string
-	}{
-		{
-			name: "test should work for different kinds of HTML tags",
-			args: args{
-				"<p>Hello World<br/>This is a TEST! :D</p>",
-			},
-			want: "Hello WorldThis is a TEST! :D",
-		},
-	}
-	for _, tt := range tests {
-		got := stripeHTMLTags(tt.args.HTML)
-		if got != tt.want {
-			t.Errorf("%q. stripeHTMLTags() = %v, want %v", tt.name, got, tt.want)
-		}
-	}
-}
-
-func Test_fixApostrophesAndQuotes(t *testing.T) {
-	type args struct {
-		text string
-	}
-	tests := []struct {
-		name string
-		args args
-		want string
-	}{
-		{
-			name: "test should work for apostrophes and quotes in the strings",
-			args: args{
-				"I&#39;m not strong enough to stay away\nCan&#39;t run from &#34;you&#34;...",
-			},
-			want: "I'm not strong enough to stay away\nCan't run from \"you\"...",
-		},
-	}
-	for _, tt := range tests {
-		if got := fixApostrophesAndQuotes(tt.args.text); got != tt.want {
-			t.Errorf("%q. fixApostrophes() = %v, want %v", tt.name, got, tt.want)
-		}
-	}
-}
-
-func Test_getSearchURI(t *testing.T) {
-	type args struct {
-		query string
-	}
-	tests := []struct {
-		name string
-		args args
-		want string
-	}{
-		{
-			name: "test should return right URI",
-			args: args{
-				"blackfield:pain",
-			},
-			want: "http://lyrics.wikia.com/index.php?action=ajax&rs=getLinkSuggest&format=json&query=blackfield%3Apain",
-		},
-	}
-	for _, tt := range tests {
-		if got := getSearchURI(tt.args.query); got != tt.want {
-			t.Errorf("%q. getSearchURI() = %v, want %v", tt.name, got, tt.want)
-		}
-	}
-}
-
-func Test_getFormattedLyrics(t *testing.T) {
-	type args struct {
-		text string
-	}
-	tests := []struct {
-		name string
-		args args
-		want string
-	}{
-		{
-			name: "test should return a correct formatted string from HTML lyrics",
-			args: args{
-				"<div class='lyricbox'>The shortest song in the universe<br/>Really isn't much fun<br/>It only has one puny verse<br/>. . . and then it's done!<div class='lyricsbreak'></div>\n</div>",
-			},
-			want: "The shortest song in the universe\nReally isn't much fun\nIt only has one puny verse\n. . . and then it's done!\n",
-		},
-	}
-	for _, tt := range tests {
-		got := getFormattedLyrics(tt.args.text)
-		if got != tt.want {
-			t.Errorf("%q. getFormattedLyrics() = %v, want %v", tt.name, got, tt.want)
-		}
-	}
-}
-
 func TestSearchTrack(t *testing.T) {
 	type args struct {
 		query string
@@ -247,7 +111,7 @@ func TestTrack_FetchLyrics(t *testing.T) {
 			fields: fields{
 				Artist: "Sandra_Boynton",
 				Name:   "The_Shortest_Song_In_The_Universe",
-				Lyrics: "The shortest song in the universe\nReally isn't much fun\nIt only has one puny verse\n... And then it's done!\n",
+				Lyrics: "The shortest song in the universe\nReally isn't much fun\nIt only has one puny verse\n... And then it's done!",
 			},
 		},
 	}
@@ -267,3 +131,21 @@ func TestTrack_FetchLyrics(t *testing.T) {
 		})
 	}
 }
+
+func TestTrack_addFeaturing(t *testing.T) {
+	track := Track{Artist: "Blackfield", Name: "Once"}
+	if track != (Track{Artist: "Blackfield", Name: "Once"}) {
+		t.Errorf("Track == Track = false, want true")
+	}
+	for _, name := range []string{"Aviv Geffen", "", "Aviv Geffen"} {
+		track.addFeaturing(name)
+	}
+	track.addWriter("Steven Wilson")
+	want := &TrackMetadata{Featuring: []string{"Aviv Geffen"}, Writers: []string{"Steven Wilson"}}
+	if !reflect.DeepEqual(track.Metadata, want) {
+		t.Errorf("Track.addFeaturing() metadata = %+v, want %+v", track.Metadata, want)
+	}
+	if clone := track.clone(); clone.Metadata == track.Metadata || !reflect.DeepEqual(clone.Metadata, want) {
+		t.Errorf("Track.clone() metadata = %p %+v, want a copy of %p", clone.Metadata, clone.Metadata, track.Metadata)
+	}
+}
@@ -0,0 +1,149 @@
+package golyrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+)
+
+// DefaultMaxResponseSize is the default limit for the size of response bodies.
+const DefaultMaxResponseSize = 5 << 20
+
+// maxDrainSize is how much of an unread body is discarded before closing it,
+// so that small responses let their connection be reused.
+const maxDrainSize = 64 << 10
+
+// Do sends an HTTP request on behalf of a Provider,
+// applying the Client's settings such as its User-Agent and retry policy.
+// Unsuccessful responses are closed and returned as a *StatusError.
+// When the Client revalidates cached lyrics, the request may be made
+// conditional and fail with ErrNotModified, which Providers should return.
+// Otherwise the caller must close the response body,
+// which fails with ErrTooLarge when read past the Client's limit.
+func (c *Client) Do(request *http.Request) (*http.Response, error) {
+	r := revalidationFrom(request.Context())
+	if r != nil {
+		r.prepare(request)
+	}
+	response, err := c.retry(request, c.send)
+	if err == nil && r != nil {
+		r.record(response)
+	}
+	return response, err
+}
+
+// send sends request once.
+func (c *Client) send(request *http.Request) (*http.Response, error) {
+	if c.userAgent != "" && request.Header.Get("User-Agent") == "" {
+		request.Header.Set("User-Agent", c.userAgent)
+	}
+	response, err := c.roundTrip(request)
+	if err != nil {
+		return nil, contextError(request.Context(), err)
+	}
+	if response.StatusCode == http.StatusNotModified {
+		closeBody(response.Body)
+		return nil, ErrNotModified
+	}
+	if err := checkStatus(response); err != nil {
+		closeBody(response.Body)
+		return nil, err
+	}
+	if c.maxBodySize > 0 && response.ContentLength > c.maxBodySize {
+		closeBody(response.Body)
+		return nil, ErrTooLarge
+	}
+	if c.maxBodySize > 0 {
+		response.Body = &limitedBody{ReadCloser: response.Body, remaining: c.maxBodySize}
+	}
+	return response, nil
+}
+
+// roundTrip sends request with the Client's http.Client,
+// waiting for the rate limiter of its host first.
+func (c *Client) roundTrip(request *http.Request) (*http.Response, error) {
+	if c.rateLimiter == nil {
+		return c.httpClient.Do(request)
+	}
+	return c.rateLimiter.limit(request, c.httpClient.Do)
+}
+
+// get sends a GET request for URI and reads the whole response body,
+// checking that its media type is one of contentTypes.
+func (c *Client) get(ctx context.Context, URI string, contentTypes ...string) ([]byte, error) {
+	request, err := http.NewRequest(http.MethodGet, URI, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(response.Body)
+	if err := checkContentType(response, contentTypes); err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	return data, nil
+}
+
+// contextError returns the error of ctx if it is done, or err otherwise,
+// so a cancelled lookup reports why it stopped instead of a transport error.
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// closeBody drains what is left of body, up to maxDrainSize, and closes it.
+func closeBody(body io.ReadCloser) {
+	io.CopyN(ioutil.Discard, body, maxDrainSize)
+	body.Close()
+}
+
+// checkContentType returns a *ParseError if the media type of response
+// is not one of contentTypes. Responses without a Content-Type are accepted.
+func checkContentType(response *http.Response, contentTypes []string) error {
+	header := response.Header.Get("Content-Type")
+	if header == "" || len(contentTypes) == 0 {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err == nil {
+		for _, contentType := range contentTypes {
+			if mediaType == contentType {
+				return nil
+			}
+		}
+		err = fmt.Errorf("unexpected content type %q", mediaType)
+	}
+	return &ParseError{URL: response.Request.URL.String(), Err: err}
+}
+
+// limitedBody fails with ErrTooLarge once more than remaining bytes are read.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrTooLarge
+	}
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), ErrTooLarge
+	}
+	return n, err
+}
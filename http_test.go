@@ -0,0 +1,114 @@
+package golyrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// closeCountingTransport counts the response bodies that were closed.
+type closeCountingTransport struct {
+	closed int32
+}
+
+type countedBody struct {
+	io.ReadCloser
+	closed *int32
+}
+
+func (b countedBody) Close() error {
+	atomic.AddInt32(b.closed, 1)
+	return b.ReadCloser.Close()
+}
+
+func (t *closeCountingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := http.DefaultTransport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	response.Body = countedBody{ReadCloser: response.Body, closed: &t.closed}
+	return response, nil
+}
+
+func TestClient_get(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			fmt.Fprint(w, `{"suggestions":[]}`)
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html></html>`)
+		case "/large":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, strings.Repeat(" ", 100))
+		case "/chunked":
+			w.Header().Set("Content-Type", "application/json")
+			for i := 0; i < 10; i++ {
+				fmt.Fprint(w, strings.Repeat(" ", 10))
+				w.(http.Flusher).Flush()
+			}
+		case "/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, "go away")
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "should read bodies with an accepted content type",
+			path: "/json",
+			want: `{"suggestions":[]}`,
+		},
+		{
+			name:    "should reject bodies with other content types",
+			path:    "/html",
+			wantErr: ErrParse,
+		},
+		{
+			name:    "should reject bodies declared larger than the limit",
+			path:    "/large",
+			wantErr: ErrTooLarge,
+		},
+		{
+			name:    "should stop reading bodies growing larger than the limit",
+			path:    "/chunked",
+			wantErr: ErrTooLarge,
+		},
+		{
+			name:    "should return unsuccessful statuses as errors",
+			path:    "/forbidden",
+			wantErr: &StatusError{},
+		},
+	}
+	for _, tt := range tests {
+		transport := &closeCountingTransport{}
+		client := NewClient(WithTransport(transport), WithMaxResponseSize(50))
+		got, err := client.get(context.Background(), server.URL+tt.path, "application/json")
+		if statusErr, ok := tt.wantErr.(*StatusError); ok {
+			if !errors.As(err, &statusErr) {
+				t.Errorf("%q. Client.get() error = %v, want a *StatusError", tt.name, err)
+			}
+		} else if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%q. Client.get() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%q. Client.get() = %q, want %q", tt.name, got, tt.want)
+		}
+		if closed := atomic.LoadInt32(&transport.closed); closed != 1 {
+			t.Errorf("%q. Client.get() closed the body %d times, want 1", tt.name, closed)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package golyrics
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// search is SearchContext with caching and de-duplication.
+func (c *Client) search(ctx context.Context, query string) ([]Track, error) {
+	key := c.searchCacheKey(query)
+	if entry, ok := c.cacheGet(key); ok {
+		if entry.Tracks == nil {
+			return []Track{}, nil
+		}
+		return entry.Tracks, nil
+	}
+	value, err := c.flights.do(ctx, key, func(ctx context.Context) (interface{}, error) {
+		tracks, err := c.provider.Search(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		c.cacheSet(key, &cacheEntry{Tracks: tracks})
+		return tracks, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]Track{}, value.([]Track)...), nil
+}
+
+// fetched is the outcome of a fetch shared by its waiters.
+type fetched struct {
+	track  Track
+	result Result
+}
+
+// fetch is FetchResult with caching, revalidation and de-duplication.
+func (c *Client) fetch(ctx context.Context, track *Track) (*Result, error) {
+	key := c.lyricsCacheKey(track)
+	entry, cached := c.cacheGet(key)
+	if cached && !entry.stale(time.Now()) {
+		if entry.Stub {
+			return nil, ErrStub
+		}
+		if entry.NotFound || entry.Track == nil {
+			return nil, ErrNotFound
+		}
+		track.merge(*entry.Track)
+		return &Result{Provider: entry.providerName(c), Cached: true}, nil
+	}
+	var known map[string]validator
+	if cached && entry.Track != nil {
+		known = entry.Validators
+	}
+
+	// Providers are only given what identifies the track, so that what
+	// they return can be cached and shared without the fields of the caller.
+	request := Track{Artist: track.Artist, Name: track.Name}
+	value, err := c.flights.do(ctx, key, func(ctx context.Context) (interface{}, error) {
+		ctx, r := withRevalidation(ctx, known)
+		track := request
+		var result *Result
+		var err error
+		if fetcher, ok := c.provider.(ResultFetcher); ok {
+			result, err = fetcher.FetchResult(ctx, &track)
+		} else if err = c.provider.Fetch(ctx, &track); err == nil {
+			result = &Result{Provider: c.provider.Name()}
+		}
+		switch {
+		case errors.Is(err, ErrNotModified) && known != nil:
+			entry.Expires = 0
+			c.cacheSet(key, entry)
+			return &fetched{
+				track:  *entry.Track,
+				result: Result{Provider: entry.providerName(c), Cached: true, Revalidated: true},
+			}, nil
+		case err == nil:
+			if track.Artist == request.Artist && track.Name == request.Name {
+				// Keep the artist and name of each caller when the provider
+				// found the track under the name it was asked for.
+				track.Artist, track.Name = "", ""
+			}
+			track.Provider = result.Provider
+			track.FetchedAt = time.Now()
+			cached := track
+			c.cacheSet(key, &cacheEntry{Track: &cached, Provider: result.Provider, Validators: r.validators()})
+		case errors.Is(err, ErrNotFound):
+			c.cacheSet(key, &cacheEntry{NotFound: true, Stub: errors.Is(err, ErrStub)})
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &fetched{track: track, result: *result}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	shared := value.(*fetched)
+	track.merge(shared.track)
+	result := shared.result
+	result.Failures = append([]ProviderError(nil), result.Failures...)
+	return &result, nil
+}
+
+// clone returns a copy of t that shares no memory with it.
+func (t Track) clone() Track {
+	if t.Metadata != nil {
+		t.Metadata = &TrackMetadata{
+			Featuring: append([]string(nil), t.Metadata.Featuring...),
+			Writers:   append([]string(nil), t.Metadata.Writers...),
+		}
+	}
+	if t.Synced != nil {
+		synced := t.Synced.clone()
+		t.Synced = &synced
+	}
+	return t
+}
+
+// merge sets the fields of track that a provider fetched in f,
+// keeping the others. Featured artists and writers are added to
+// those of track, without changing the TrackMetadata it points to.
+func (track *Track) merge(f Track) {
+	if f.Artist != "" {
+		track.Artist = f.Artist
+	}
+	if f.Name != "" {
+		track.Name = f.Name
+	}
+	track.Lyrics = f.Lyrics
+	if f.Album != "" {
+		track.Album = f.Album
+	}
+	if f.Year != 0 {
+		track.Year = f.Year
+	}
+	if f.TrackNumber != 0 {
+		track.TrackNumber = f.TrackNumber
+	}
+	if f.Language != "" {
+		track.Language = f.Language
+	}
+	if f.URL != "" {
+		track.URL = f.URL
+	}
+	if f.Metadata != nil {
+		if track.Metadata != nil {
+			*track = track.clone()
+		}
+		for _, name := range f.Metadata.Featuring {
+			track.addFeaturing(name)
+		}
+		for _, name := range f.Metadata.Writers {
+			track.addWriter(name)
+		}
+	}
+	if f.Synced != nil {
+		synced := f.Synced.clone()
+		track.Synced = &synced
+	}
+	track.Provider = f.Provider
+	track.FetchedAt = f.FetchedAt
+}
+
+// providerName returns the provider that answered for entry,
+// falling back to the provider of c for entries that did not record it.
+func (entry *cacheEntry) providerName(c *Client) string {
+	if entry.Provider != "" {
+		return entry.Provider
+	}
+	return c.provider.Name()
+}
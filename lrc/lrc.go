@@ -0,0 +1,390 @@
+// Package lrc parses and writes LRC files, the de facto format
+// of synchronized lyrics, where every line is tagged with the time
+// it is sung at:
+//
+//	[ar:Blackfield]
+//	[ti:Pain]
+//	[00:12.00]Pain
+//	[00:15.30][01:02.10]Can't run
+//
+// Enhanced LRC files also time every word, with tags inside the lines:
+//
+//	[00:15.30]<00:15.30>Can't <00:15.90>run <00:16.80>
+//
+// Parsing is tolerant: lines and tags that are not understood are skipped,
+// so that the many variants found in the wild can still be read.
+package lrc
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mamal72/golyrics"
+)
+
+// Line is a line of lyrics and the time it starts at.
+type Line struct {
+	Time time.Duration
+	// Text is the text of the line, without word times.
+	Text string
+	// Words holds the words of enhanced LRC lines, as written:
+	// their text keeps the spaces following them, and a last word
+	// without text marks when the line ends.
+	// When set, they are written instead of Text.
+	Words []Word
+}
+
+// Word is a word of an enhanced LRC line and the time it starts at.
+type Word struct {
+	Time time.Duration
+	Text string
+}
+
+// File is a parsed LRC file.
+type File struct {
+	// Artist, Title and Album are read from the [ar:], [ti:] and [al:] tags.
+	Artist string
+	Title  string
+	Album  string
+	// Length is the length of the song, read from the [length:] tag.
+	Length time.Duration
+	// Offset is read from the [offset:] tag. A positive offset makes
+	// every line start earlier. Times of lines do not include it;
+	// use ApplyOffset to add it to them.
+	Offset time.Duration
+	// Tags holds the other tags of the file, such as [au:] or [by:],
+	// keyed by their lowercase name.
+	Tags map[string]string
+	// Lines holds the lines of the file, sorted by time.
+	// Lines with several times appear once per time.
+	Lines []Line
+}
+
+var (
+	timePattern = regexp.MustCompile(`^\s*(\d+):(\d{1,2})(?:[.:](\d{1,3}))?\s*$`)
+	tagPattern  = regexp.MustCompile(`^\[\s*([A-Za-z#]+)\s*:(.*?)\]?\s*$`)
+	wordPattern = regexp.MustCompile(`<([^<>]*)>`)
+	// newlines replaces the line breaks of values being written.
+	newlines = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+)
+
+// Read reads and parses an LRC file from r.
+func Read(r io.Reader) (File, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return File{}, err
+	}
+	return Parse(string(data)), nil
+}
+
+// Parse parses the text of an LRC file.
+// It never fails: lines and tags it does not understand are skipped.
+func Parse(text string) File {
+	var f File
+	text = strings.TrimPrefix(text, "\ufeff")
+	text = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(text)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		var times []time.Duration
+		for strings.HasPrefix(line, "[") {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				break
+			}
+			t, ok := parseTime(line[1:end])
+			if !ok {
+				break
+			}
+			times = append(times, t)
+			line = strings.TrimSpace(line[end+1:])
+		}
+		if len(times) > 0 {
+			text, words := line, parseWords(line, times[0])
+			if words != nil {
+				text = wordsText(words)
+			}
+			// Word times are for the first time of the line,
+			// and are moved along with it for the others.
+			for _, t := range times {
+				f.Lines = append(f.Lines, Line{Time: t, Text: text, Words: shiftWords(words, t-times[0])})
+			}
+			continue
+		}
+		if match := tagPattern.FindStringSubmatch(line); match != nil {
+			f.setTag(strings.ToLower(match[1]), strings.TrimSpace(match[2]))
+		}
+	}
+	sort.SliceStable(f.Lines, func(i, j int) bool {
+		return f.Lines[i].Time < f.Lines[j].Time
+	})
+	return f
+}
+
+// parseWords splits an enhanced LRC line starting at start into its timed words.
+// It returns nil for lines without word times.
+func parseWords(line string, start time.Duration) []Word {
+	var words []Word
+	last, at, tagged := 0, start, false
+	for _, match := range wordPattern.FindAllStringSubmatchIndex(line, -1) {
+		t, ok := parseTime(line[match[2]:match[3]])
+		if !ok {
+			continue
+		}
+		if text := line[last:match[0]]; text != "" || tagged {
+			words = append(words, Word{Time: at, Text: text})
+		}
+		last, at, tagged = match[1], t, true
+	}
+	if !tagged {
+		return nil
+	}
+	return append(words, Word{Time: at, Text: line[last:]})
+}
+
+// wordsText returns the text of a line made of words.
+func wordsText(words []Word) string {
+	var text strings.Builder
+	for _, word := range words {
+		text.WriteString(word.Text)
+	}
+	return strings.TrimSpace(text.String())
+}
+
+// shiftWords returns a copy of words moved by d, not before zero.
+func shiftWords(words []Word, d time.Duration) []Word {
+	if words == nil {
+		return nil
+	}
+	shifted := make([]Word, len(words))
+	for i, word := range words {
+		shifted[i] = Word{Time: clamp(word.Time + d), Text: word.Text}
+	}
+	return shifted
+}
+
+// clamp returns t, or zero if t is negative.
+func clamp(t time.Duration) time.Duration {
+	if t < 0 {
+		return 0
+	}
+	return t
+}
+
+// setTag sets the tag named key to value.
+// Lengths and offsets that are not numbers are skipped.
+func (f *File) setTag(key, value string) {
+	switch key {
+	case "ar":
+		f.Artist = value
+	case "ti":
+		f.Title = value
+	case "al":
+		f.Album = value
+	case "length":
+		if length, ok := parseTime(value); ok {
+			f.Length = length
+		} else if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			f.Length = time.Duration(seconds) * time.Second
+		}
+	case "offset":
+		if ms, err := strconv.Atoi(value); err == nil {
+			f.Offset = time.Duration(ms) * time.Millisecond
+		}
+	default:
+		if f.Tags == nil {
+			f.Tags = map[string]string{}
+		}
+		f.Tags[key] = value
+	}
+}
+
+// parseTime parses a time tag like 01:02.34, 01:02:34, 01:02.345 or 01:02.
+func parseTime(tag string) (time.Duration, bool) {
+	match := timePattern.FindStringSubmatch(tag)
+	if match == nil {
+		return 0, false
+	}
+	minutes, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+	seconds, _ := strconv.Atoi(match[2])
+	if seconds >= 60 {
+		return 0, false
+	}
+	t := time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+	if fraction := match[3]; fraction != "" {
+		n, _ := strconv.Atoi(fraction)
+		for i := len(fraction); i < 3; i++ {
+			n *= 10
+		}
+		t += time.Duration(n) * time.Millisecond
+	}
+	return t, true
+}
+
+// formatTime formats t as mm:ss.xx, or mm:ss.xxx when it is
+// not a whole number of hundredths of a second.
+func formatTime(t time.Duration) string {
+	if t < 0 {
+		t = 0
+	}
+	t = t.Round(time.Millisecond)
+	minutes := t / time.Minute
+	seconds := (t % time.Minute) / time.Second
+	ms := (t % time.Second) / time.Millisecond
+	if ms%10 == 0 {
+		return fmt.Sprintf("%02d:%02d.%02d", minutes, seconds, ms/10)
+	}
+	return fmt.Sprintf("%02d:%02d.%03d", minutes, seconds, ms)
+}
+
+// ApplyOffset moves the lines of f by its offset and resets it,
+// so that their times are the times they are sung at.
+func (f *File) ApplyOffset() {
+	for i, line := range f.Lines {
+		f.Lines[i].Time = clamp(line.Time - f.Offset)
+		f.Lines[i].Words = shiftWords(line.Words, -f.Offset)
+	}
+	f.Offset = 0
+}
+
+// String returns f in the LRC format, with its tags first
+// and then a line for every line of f, in order.
+func (f File) String() string {
+	var text strings.Builder
+	tag := func(key, value string) {
+		fmt.Fprintf(&text, "[%s:%s]\n", key, newlines.Replace(value))
+	}
+	if f.Artist != "" {
+		tag("ar", f.Artist)
+	}
+	if f.Title != "" {
+		tag("ti", f.Title)
+	}
+	if f.Album != "" {
+		tag("al", f.Album)
+	}
+	if f.Length > 0 {
+		tag("length", strings.TrimSuffix(formatTime(f.Length), ".00"))
+	}
+	if f.Offset != 0 {
+		tag("offset", fmt.Sprintf("%+d", f.Offset/time.Millisecond))
+	}
+	keys := make([]string, 0, len(f.Tags))
+	for key := range f.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		tag(key, f.Tags[key])
+	}
+	for _, line := range f.Lines {
+		fmt.Fprintf(&text, "[%s]", formatTime(line.Time))
+		if line.Words == nil {
+			text.WriteString(newlines.Replace(line.Text))
+		}
+		for _, word := range line.Words {
+			fmt.Fprintf(&text, "<%s>%s", formatTime(word.Time), newlines.Replace(word.Text))
+		}
+		text.WriteString("\n")
+	}
+	return text.String()
+}
+
+// WriteTo writes f to w in the LRC format.
+func (f File) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, f.String())
+	return int64(n), err
+}
+
+// Track returns a track with the artist, name and album of f,
+// the text of its lines as lyrics and its lines as synced lyrics.
+func (f File) Track() golyrics.Track {
+	track := golyrics.Track{
+		Artist: f.Artist,
+		Name:   f.Title,
+		Album:  f.Album,
+	}
+	if len(f.Lines) > 0 {
+		synced := f.Synced()
+		track.Synced = &synced
+		track.Lyrics = synced.String()
+	}
+	return track
+}
+
+// Synced returns the lines of f as synced lyrics, with the offset of f applied.
+// Words without text end the word before them, and the line when they are last.
+func (f File) Synced() golyrics.SyncedLyrics {
+	var synced golyrics.SyncedLyrics
+	for _, line := range f.Lines {
+		s := golyrics.SyncedLine{Start: clamp(line.Time - f.Offset), Text: line.Text}
+		for i, word := range line.Words {
+			t := clamp(word.Time - f.Offset)
+			if text := strings.TrimSpace(word.Text); text != "" {
+				s.Words = append(s.Words, golyrics.SyncedWord{Start: t, Text: text})
+				continue
+			}
+			if n := len(s.Words); n > 0 && s.Words[n-1].End == 0 {
+				s.Words[n-1].End = t
+			}
+			if i == len(line.Words)-1 {
+				s.End = t
+			}
+		}
+		synced.Lines = append(synced.Lines, s)
+	}
+	return synced
+}
+
+// FromTrack returns a file with the artist, name and album of track.
+// Its lines are the synced lyrics of track, with their word times, if it has any.
+// Otherwise they are the lines of its lyrics, not timed yet: they all start at zero.
+func FromTrack(track golyrics.Track) File {
+	f := File{
+		Artist: track.Artist,
+		Title:  track.Name,
+		Album:  track.Album,
+	}
+	if track.Synced != nil {
+		for _, s := range track.Synced.Lines {
+			f.Lines = append(f.Lines, Line{Time: s.Start, Text: s.Text, Words: syncedWords(s)})
+		}
+		return f
+	}
+	if track.Lyrics == "" {
+		return f
+	}
+	for _, text := range strings.Split(track.Lyrics, "\n") {
+		f.Lines = append(f.Lines, Line{Text: strings.TrimSpace(text)})
+	}
+	return f
+}
+
+// syncedWords returns the words of a synced line as enhanced LRC words,
+// ending with the end of the line when it is known.
+func syncedWords(line golyrics.SyncedLine) []Word {
+	if len(line.Words) == 0 {
+		return nil
+	}
+	words := make([]Word, len(line.Words))
+	for i, word := range line.Words {
+		words[i] = Word{Time: word.Start, Text: word.Text + " "}
+	}
+	end := line.Words[len(line.Words)-1].End
+	if end == 0 {
+		end = line.End
+	}
+	if end == 0 {
+		words[len(words)-1].Text = line.Words[len(line.Words)-1].Text
+		return words
+	}
+	return append(words, Word{Time: end})
+}
@@ -0,0 +1,28 @@
+//go:build go1.18
+// +build go1.18
+
+package lrc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"[ar:Blackfield]\n[ti:Pain]\n[length:03:45]\n[offset:+250]\n[00:12.00]Pain\n[00:15.30][01:02.345]Can't run",
+		"[00:30.00][00:10.00]Chorus\r\n[1:02]a\n[01:02:50]b\n[100:00.00]",
+		"[00:15.30]<00:15.30>Can't <00:15.90>run <00:16.80>\n[00:30.00][00:10.00]Go <00:20.50><x>away",
+		"just text\n[00:61.00]late\n[ar:Blackfield\n[00:01.00][Chorus] Hey\n[ti:Song [Live]]",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		file := Parse(text)
+		got := file.String()
+		if again := Parse(got); !reflect.DeepEqual(again, file) {
+			t.Errorf("Parse(%q) = %+v, but Parse(%q) = %+v", text, file, got, again)
+		}
+	})
+}
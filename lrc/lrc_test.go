@@ -0,0 +1,319 @@
+package lrc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mamal72/golyrics"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want File
+	}{
+		{
+			name: "should parse tags and timed lines",
+			text: "[ar:Blackfield]\n[ti:Pain]\n[al:Blackfield]\n[length: 03:45]\n[offset:+250]\n[by:someone]\n" +
+				"[00:12.00]Pain\n[00:15.3]Can't run\n",
+			want: File{
+				Artist: "Blackfield",
+				Title:  "Pain",
+				Album:  "Blackfield",
+				Length: 3*time.Minute + 45*time.Second,
+				Offset: 250 * time.Millisecond,
+				Tags:   map[string]string{"by": "someone"},
+				Lines: []Line{
+					{Time: 12 * time.Second, Text: "Pain"},
+					{Time: 15300 * time.Millisecond, Text: "Can't run"},
+				},
+			},
+		},
+		{
+			name: "should repeat lines with several times and sort them",
+			text: "[00:30.00][00:10.00]Chorus\r\n[00:20.00] Verse \r\n[00:10.00]Same time",
+			want: File{Lines: []Line{
+				{Time: 10 * time.Second, Text: "Chorus"},
+				{Time: 10 * time.Second, Text: "Same time"},
+				{Time: 20 * time.Second, Text: "Verse"},
+				{Time: 30 * time.Second, Text: "Chorus"},
+			}},
+		},
+		{
+			name: "should read the variants of time tags",
+			text: "[1:02]a\n[01:02:50]b\n[01:02.345]c\n[100:00.00]d\n[00:00.00]",
+			want: File{Lines: []Line{
+				{Time: 0},
+				{Time: time.Minute + 2*time.Second, Text: "a"},
+				{Time: time.Minute + 2345*time.Millisecond, Text: "c"},
+				{Time: time.Minute + 2500*time.Millisecond, Text: "b"},
+				{Time: 100 * time.Minute, Text: "d"},
+			}},
+		},
+		{
+			name: "should skip malformed lines and tags",
+			text: "\ufeffjust text\n[00:61.00]late\n[ar:Blackfield\n[length:soon]\n[offset:x]\n[00:12.00\n" +
+				"[Chorus]\n[00:01.00][Chorus] Hey\n[ti:Song [Live]]",
+			want: File{
+				Artist: "Blackfield",
+				Title:  "Song [Live]",
+				Lines:  []Line{{Time: time.Second, Text: "[Chorus] Hey"}},
+			},
+		},
+		{
+			name: "should parse the word times of enhanced lines",
+			text: "[00:15.30]<00:15.30>Can't <00:15.90>run <00:16.80>\n[00:20.00]Go <00:20.50>away<nope>\n[00:30.00][00:40.00]<00:30.00>Pain",
+			want: File{Lines: []Line{
+				{
+					Time: 15300 * time.Millisecond,
+					Text: "Can't run",
+					Words: []Word{
+						{Time: 15300 * time.Millisecond, Text: "Can't "},
+						{Time: 15900 * time.Millisecond, Text: "run "},
+						{Time: 16800 * time.Millisecond},
+					},
+				},
+				{
+					Time:  20 * time.Second,
+					Text:  "Go away<nope>",
+					Words: []Word{{Time: 20 * time.Second, Text: "Go "}, {Time: 20500 * time.Millisecond, Text: "away<nope>"}},
+				},
+				{Time: 30 * time.Second, Text: "Pain", Words: []Word{{Time: 30 * time.Second, Text: "Pain"}}},
+				{Time: 40 * time.Second, Text: "Pain", Words: []Word{{Time: 40 * time.Second, Text: "Pain"}}},
+			}},
+		},
+		{
+			name: "should read lengths in seconds and negative offsets",
+			text: "[length:225]\n[offset:-100]",
+			want: File{Length: 225 * time.Second, Offset: -100 * time.Millisecond},
+		},
+	}
+	for _, tt := range tests {
+		if got := Parse(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. Parse() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	got, err := Read(strings.NewReader("[ti:Pain]\n[00:12.00]Pain"))
+	want := File{Title: "Pain", Lines: []Line{{Time: 12 * time.Second, Text: "Pain"}}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %+v, %v, want %+v", got, err, want)
+	}
+}
+
+func TestFile_String(t *testing.T) {
+	tests := []struct {
+		name string
+		file File
+		want string
+	}{
+		{
+			name: "should write tags and then lines",
+			file: File{
+				Artist: "Blackfield",
+				Title:  "Pain",
+				Album:  "Blackfield",
+				Length: 3*time.Minute + 45*time.Second,
+				Offset: 250 * time.Millisecond,
+				Tags:   map[string]string{"re": "golyrics", "by": "someone"},
+				Lines: []Line{
+					{Time: 12 * time.Second, Text: "Pain"},
+					{Time: 15300 * time.Millisecond, Text: "Can't run"},
+					{Time: 62345 * time.Millisecond},
+				},
+			},
+			want: "[ar:Blackfield]\n[ti:Pain]\n[al:Blackfield]\n[length:03:45]\n[offset:+250]\n[by:someone]\n[re:golyrics]\n" +
+				"[00:12.00]Pain\n[00:15.30]Can't run\n[01:02.345]\n",
+		},
+		{
+			name: "should write the word times of enhanced lines",
+			file: File{Lines: []Line{{
+				Time:  15300 * time.Millisecond,
+				Text:  "ignored",
+				Words: []Word{{Time: 15300 * time.Millisecond, Text: "Can't "}, {Time: 15900 * time.Millisecond, Text: "run "}, {Time: 16800 * time.Millisecond}},
+			}}},
+			want: "[00:15.30]<00:15.30>Can't <00:15.90>run <00:16.80>\n",
+		},
+		{
+			name: "should keep values on their line",
+			file: File{Title: "Two\nlines", Lines: []Line{{Text: "One\r\ntwo"}}},
+			want: "[ti:Two lines]\n[00:00.00]One two\n",
+		},
+		{
+			name: "should write nothing for empty files",
+			file: File{},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.file.String(); got != tt.want {
+			t.Errorf("%q. File.String() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFile_String_roundTrip(t *testing.T) {
+	file := Parse("[ar:Blackfield]\n[ti:Song [Live]]\n[offset:-100]\n[au:Steven Wilson]\n" +
+		"[00:30.00][00:10.00]Chorus\n[00:20.456]Verse\n[00:25.00]\n[00:26.00]Go <00:26.50>away <00:27.00>")
+	if got := Parse(file.String()); !reflect.DeepEqual(got, file) {
+		t.Errorf("Parse(File.String()) = %+v, want %+v", got, file)
+	}
+}
+
+func TestFile_WriteTo(t *testing.T) {
+	var out strings.Builder
+	file := File{Title: "Pain", Lines: []Line{{Time: 12 * time.Second, Text: "Pain"}}}
+	n, err := file.WriteTo(&out)
+	if want := "[ti:Pain]\n[00:12.00]Pain\n"; err != nil || out.String() != want || n != int64(len(want)) {
+		t.Errorf("File.WriteTo() wrote %q, %d, %v, want %q", out.String(), n, err, want)
+	}
+}
+
+func TestFile_ApplyOffset(t *testing.T) {
+	file := File{
+		Offset: 500 * time.Millisecond,
+		Lines: []Line{
+			{Time: 200 * time.Millisecond, Text: "a"},
+			{Time: 2 * time.Second, Text: "b", Words: []Word{{Time: 2 * time.Second, Text: "b"}}},
+		},
+	}
+	file.ApplyOffset()
+	want := File{Lines: []Line{
+		{Time: 0, Text: "a"},
+		{Time: 1500 * time.Millisecond, Text: "b", Words: []Word{{Time: 1500 * time.Millisecond, Text: "b"}}},
+	}}
+	if !reflect.DeepEqual(file, want) {
+		t.Errorf("File.ApplyOffset() = %+v, want %+v", file, want)
+	}
+}
+
+func TestFile_Track(t *testing.T) {
+	file := Parse("[ar:Blackfield]\n[ti:Pain]\n[al:Blackfield]\n[00:12.00]Pain\n[00:14.00]\n[00:15.30]Can't run")
+	want := golyrics.Track{
+		Artist: "Blackfield",
+		Name:   "Pain",
+		Album:  "Blackfield",
+		Lyrics: "Pain\n\nCan't run",
+		Synced: &golyrics.SyncedLyrics{Lines: []golyrics.SyncedLine{
+			{Start: 12 * time.Second, Text: "Pain"},
+			{Start: 14 * time.Second},
+			{Start: 15300 * time.Millisecond, Text: "Can't run"},
+		}},
+	}
+	if got := file.Track(); !reflect.DeepEqual(got, want) {
+		t.Errorf("File.Track() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFile_Synced(t *testing.T) {
+	file := Parse("[offset:+300]\n[00:12.00]Pain\n[00:15.30]<00:15.30>Can't <00:15.90>run <00:16.80>\n[00:20.00]<00:20.00>Go <00:20.50><00:21.00>away")
+	want := golyrics.SyncedLyrics{Lines: []golyrics.SyncedLine{
+		{Start: 11700 * time.Millisecond, Text: "Pain"},
+		{
+			Start: 15 * time.Second,
+			End:   16500 * time.Millisecond,
+			Text:  "Can't run",
+			Words: []golyrics.SyncedWord{
+				{Start: 15 * time.Second, Text: "Can't"},
+				{Start: 15600 * time.Millisecond, End: 16500 * time.Millisecond, Text: "run"},
+			},
+		},
+		{
+			Start: 19700 * time.Millisecond,
+			Text:  "Go away",
+			Words: []golyrics.SyncedWord{
+				{Start: 19700 * time.Millisecond, End: 20200 * time.Millisecond, Text: "Go"},
+				{Start: 20700 * time.Millisecond, Text: "away"},
+			},
+		},
+	}}
+	if got := file.Synced(); !reflect.DeepEqual(got, want) {
+		t.Errorf("File.Synced() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFromTrack(t *testing.T) {
+	tests := []struct {
+		name  string
+		track golyrics.Track
+		want  File
+	}{
+		{
+			name:  "should hold the lyrics as untimed lines",
+			track: golyrics.Track{Artist: "Blackfield", Name: "Pain", Album: "Blackfield", Year: 2004, Lyrics: "Pain\n\nCan't run "},
+			want: File{
+				Artist: "Blackfield",
+				Title:  "Pain",
+				Album:  "Blackfield",
+				Lines:  []Line{{Text: "Pain"}, {}, {Text: "Can't run"}},
+			},
+		},
+		{
+			name: "should hold the synced lyrics with their word times",
+			track: golyrics.Track{Name: "Pain", Lyrics: "ignored", Synced: &golyrics.SyncedLyrics{Lines: []golyrics.SyncedLine{
+				{Start: 12 * time.Second, Text: "Pain"},
+				{
+					Start: 15 * time.Second,
+					End:   17 * time.Second,
+					Text:  "Can't run",
+					Words: []golyrics.SyncedWord{{Start: 15 * time.Second, Text: "Can't"}, {Start: 16 * time.Second, Text: "run"}},
+				},
+				{
+					Start: 20 * time.Second,
+					Text:  "Go away",
+					Words: []golyrics.SyncedWord{{Start: 20 * time.Second, Text: "Go"}, {Start: 21 * time.Second, Text: "away"}},
+				},
+			}}},
+			want: File{Title: "Pain", Lines: []Line{
+				{Time: 12 * time.Second, Text: "Pain"},
+				{
+					Time:  15 * time.Second,
+					Text:  "Can't run",
+					Words: []Word{{Time: 15 * time.Second, Text: "Can't "}, {Time: 16 * time.Second, Text: "run "}, {Time: 17 * time.Second}},
+				},
+				{
+					Time:  20 * time.Second,
+					Text:  "Go away",
+					Words: []Word{{Time: 20 * time.Second, Text: "Go "}, {Time: 21 * time.Second, Text: "away"}},
+				},
+			}},
+		},
+		{
+			name:  "should have no lines for tracks without lyrics",
+			track: golyrics.Track{Artist: "Blackfield", Name: "Pain"},
+			want:  File{Artist: "Blackfield", Title: "Pain"},
+		},
+	}
+	for _, tt := range tests {
+		if got := FromTrack(tt.track); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. FromTrack() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func Test_parseTime(t *testing.T) {
+	tests := []struct {
+		tag    string
+		want   time.Duration
+		wantOk bool
+	}{
+		{tag: "00:12.34", want: 12340 * time.Millisecond, wantOk: true},
+		{tag: " 2:03 ", want: 2*time.Minute + 3*time.Second, wantOk: true},
+		{tag: "00:00:05", want: 50 * time.Millisecond, wantOk: true},
+		{tag: "00:60.00"},
+		{tag: "ar:Blackfield"},
+		{tag: "-1:00.00"},
+		{tag: ""},
+	}
+	for _, tt := range tests {
+		got, ok := parseTime(tt.tag)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("%q. parseTime() = %v, %v, want %v, %v", tt.tag, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
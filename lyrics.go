@@ -0,0 +1,241 @@
+package golyrics
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Lyrics are the lyrics of a song split into stanzas and lines,
+// so that sections and single lines can be addressed.
+// Use ParseLyrics or Track.ParsedLyrics to get them.
+type Lyrics struct {
+	Stanzas []Stanza
+}
+
+// Stanza is a group of lines, separated from the others
+// by a blank line or started by a section label.
+type Stanza struct {
+	// Label names the section of the song, like "Chorus" or "Verse 2",
+	// when the lyrics mark it with a line like [Chorus].
+	Label string
+	// Repeat is how many times the stanza is sung, when the lyrics
+	// mark it with a count like (x2), or zero.
+	Repeat int
+	Lines  []Line
+
+	// header and footer are the lines holding the label
+	// and the repeat count, as written.
+	header, footer string
+	// attached is set when no blank line separates the stanza
+	// from the previous one.
+	attached bool
+}
+
+// Line is a line of lyrics.
+type Line struct {
+	// Text is the line without its repeat count.
+	Text string
+	// Repeat is how many times the line is sung, when the lyrics
+	// mark it with a count like (x2), or zero.
+	Repeat int
+
+	// marker is the repeat count ending the line, as written.
+	marker string
+}
+
+var (
+	// repeatPattern matches a repeat count like (x2), [2x] or (×3).
+	repeatPattern = regexp.MustCompile(`(?i)\s*[(\[]\s*(?:(?:x|×)\s*(\d+)|(\d+)\s*(?:x|×))\s*[)\]]\s*$`)
+	// labelRepeatPattern matches a repeat count like x2 ending a label.
+	labelRepeatPattern = regexp.MustCompile(`(?i)\s+(?:(?:x|×)\s*(\d+)|(\d+)\s*(?:x|×))$`)
+	// bracketLabelPattern matches a line holding only a [Label].
+	bracketLabelPattern = regexp.MustCompile(`^\s*\[\s*([^\]]+?)\s*\]\s*:?\s*$`)
+	// sectionLabelPattern matches a line holding only a (Label) or Label:
+	// naming a section, as parentheses and colons are common in lyrics too.
+	sectionLabelPattern = regexp.MustCompile(`(?i)^\s*(?:\(\s*([^)]+?)\s*\)|([^:()\[\]]+?)\s*:)\s*$`)
+	sectionPattern      = regexp.MustCompile(`(?i)^(?:pre-?chorus|post-?chorus|chorus|verse|bridge|intro|outro|hook|refrain|interlude|instrumental|solo|breakdown|coda|spoken|rap)\b`)
+)
+
+// ParseLyrics parses plain text lyrics. Stanzas are separated by blank lines
+// or started by section labels like [Chorus], [Verse 2], (Bridge) or Outro:,
+// and repeat counts like (x2) are read from the end of lines, from labels
+// and from lines holding only a count at the end of a stanza.
+//
+// The String method of the result returns text, except for extra blank lines
+// and lines with only whitespace, which are dropped.
+func ParseLyrics(text string) Lyrics {
+	var lyrics Lyrics
+	var stanza *Stanza
+	blank := true
+	finish := func() {
+		if stanza != nil {
+			lyrics.Stanzas = append(lyrics.Stanzas, *stanza)
+			stanza = nil
+		}
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, raw := range lines {
+		if strings.TrimSpace(raw) == "" {
+			finish()
+			blank = true
+			continue
+		}
+		if label, repeat, ok := parseLabel(raw); ok {
+			finish()
+			stanza = &Stanza{Label: label, Repeat: repeat, header: raw, attached: !blank}
+			blank = false
+			continue
+		}
+		text, repeat, marker := splitRepeat(raw)
+		if text == "" && stanza != nil && len(stanza.Lines) > 0 && stanza.footer == "" && stanza.Repeat == 0 &&
+			endsStanza(lines, i+1) {
+			stanza.Repeat, stanza.footer = repeat, raw
+			continue
+		}
+		if text == "" {
+			text, repeat, marker = raw, 0, ""
+		}
+		if stanza == nil {
+			stanza = &Stanza{attached: !blank}
+		}
+		stanza.Lines = append(stanza.Lines, Line{Text: text, Repeat: repeat, marker: marker})
+		blank = false
+	}
+	finish()
+	return lyrics
+}
+
+// parseLabel parses a line holding only a section label,
+// possibly followed by a repeat count.
+func parseLabel(line string) (label string, repeat int, ok bool) {
+	if text, _, _ := splitRepeat(line); strings.TrimSpace(text) == "" {
+		return "", 0, false
+	}
+	if match := bracketLabelPattern.FindStringSubmatch(line); match != nil {
+		label = match[1]
+	} else if match := sectionLabelPattern.FindStringSubmatch(line); match != nil {
+		label = match[1] + match[2]
+		if !sectionPattern.MatchString(label) {
+			return "", 0, false
+		}
+	} else {
+		return "", 0, false
+	}
+	label, repeat, _ = splitRepeat(label)
+	if match := labelRepeatPattern.FindStringSubmatch(label); match != nil {
+		repeat, _ = strconv.Atoi(match[1] + match[2])
+		label = label[:len(label)-len(match[0])]
+	}
+	return label, repeat, label != ""
+}
+
+// splitRepeat splits the repeat count from the end of line.
+func splitRepeat(line string) (text string, repeat int, marker string) {
+	match := repeatPattern.FindStringSubmatchIndex(line)
+	if match == nil {
+		return line, 0, ""
+	}
+	var digits string
+	if match[2] >= 0 {
+		digits = line[match[2]:match[3]]
+	} else {
+		digits = line[match[4]:match[5]]
+	}
+	repeat, _ = strconv.Atoi(digits)
+	return line[:match[0]], repeat, line[match[0]:]
+}
+
+// endsStanza reports whether the stanza ends before lines[i].
+func endsStanza(lines []string, i int) bool {
+	if i >= len(lines) || strings.TrimSpace(lines[i]) == "" {
+		return true
+	}
+	_, _, ok := parseLabel(lines[i])
+	return ok
+}
+
+// String returns the lyrics as plain text, with stanzas separated by blank lines.
+func (l Lyrics) String() string {
+	var text strings.Builder
+	for i, stanza := range l.Stanzas {
+		if i > 0 {
+			text.WriteString("\n")
+			if !stanza.attached {
+				text.WriteString("\n")
+			}
+		}
+		text.WriteString(stanza.String())
+	}
+	return text.String()
+}
+
+// Lines returns the lines of every stanza, in order.
+func (l Lyrics) Lines() []Line {
+	var lines []Line
+	for _, stanza := range l.Stanzas {
+		lines = append(lines, stanza.Lines...)
+	}
+	return lines
+}
+
+// String returns the stanza as plain text, with its label and repeat count.
+// They are written as they were parsed unless Label or Repeat changed.
+func (s Stanza) String() string {
+	header, footer := s.header, s.footer
+	if !s.unchanged() {
+		header, footer = "", ""
+	}
+	var lines []string
+	switch {
+	case header != "":
+		lines = append(lines, header)
+	case s.Label != "":
+		lines = append(lines, "["+s.Label+"]")
+	}
+	for _, line := range s.Lines {
+		lines = append(lines, line.String())
+	}
+	switch {
+	case footer != "":
+		lines = append(lines, footer)
+	case header == "" && s.Repeat > 1:
+		lines = append(lines, fmt.Sprintf("(x%d)", s.Repeat))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// unchanged reports whether the header and footer of s
+// still read as its Label and Repeat.
+func (s Stanza) unchanged() bool {
+	var label string
+	var repeat int
+	if s.header != "" {
+		label, repeat, _ = parseLabel(s.header)
+	}
+	if s.footer != "" {
+		_, repeat, _ = splitRepeat(s.footer)
+	}
+	return label == s.Label && repeat == s.Repeat
+}
+
+// String returns the line as plain text, with its repeat count.
+// It is written as it was parsed unless Repeat changed.
+func (l Line) String() string {
+	if l.marker != "" {
+		if _, repeat, _ := splitRepeat(l.marker); repeat == l.Repeat {
+			return l.Text + l.marker
+		}
+	}
+	if l.Repeat > 1 {
+		return fmt.Sprintf("%s (x%d)", l.Text, l.Repeat)
+	}
+	return l.Text
+}
+
+// ParsedLyrics returns the lyrics of the track parsed by ParseLyrics.
+func (t Track) ParsedLyrics() Lyrics {
+	return ParseLyrics(t.Lyrics)
+}
@@ -0,0 +1,28 @@
+//go:build go1.18
+// +build go1.18
+
+package golyrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func FuzzParseLyrics(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"Pain\nCan't run\n\n\nAway",
+		"[Verse 1]\nOne\n[Chorus x2]\nTwo\n\n(Bridge)\nThree\nOutro:\nFour",
+		"Hey (x3)\nHo [2x]\n(x2)\n\n(x4)\nStill a line",
+		"[x2]\n(x2)\n[]\n[ ]\nChorus:\n:\n",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		lyrics := ParseLyrics(text)
+		got := lyrics.String()
+		if again := ParseLyrics(got); !reflect.DeepEqual(again, lyrics) {
+			t.Errorf("ParseLyrics(%q) = %+v, but ParseLyrics(%q) = %+v", text, lyrics, got, again)
+		}
+	})
+}
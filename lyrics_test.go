@@ -0,0 +1,157 @@
+package golyrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLyrics(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Lyrics
+	}{
+		{
+			name: "should split stanzas at blank lines",
+			text: "Pain\nCan't run\n\n\nAway",
+			want: Lyrics{Stanzas: []Stanza{
+				{Lines: []Line{{Text: "Pain"}, {Text: "Can't run"}}},
+				{Lines: []Line{{Text: "Away"}}},
+			}},
+		},
+		{
+			name: "should start stanzas at section labels",
+			text: "[Verse 1]\nOne\n[Chorus x2]\nTwo\n\n(Bridge)\nThree\nOutro:\nFour",
+			want: Lyrics{Stanzas: []Stanza{
+				{Label: "Verse 1", Lines: []Line{{Text: "One"}}, header: "[Verse 1]"},
+				{Label: "Chorus", Repeat: 2, Lines: []Line{{Text: "Two"}}, header: "[Chorus x2]", attached: true},
+				{Label: "Bridge", Lines: []Line{{Text: "Three"}}, header: "(Bridge)"},
+				{Label: "Outro", Lines: []Line{{Text: "Four"}}, header: "Outro:", attached: true},
+			}},
+		},
+		{
+			name: "should read repeat counts of lines and stanzas",
+			text: "Hey (x3)\nHo [2x]\n(x2)\n\n(x4)\nStill a line",
+			want: Lyrics{Stanzas: []Stanza{
+				{
+					Repeat: 2,
+					Lines:  []Line{{Text: "Hey", Repeat: 3, marker: " (x3)"}, {Text: "Ho", Repeat: 2, marker: " [2x]"}},
+					footer: "(x2)",
+				},
+				{Lines: []Line{{Text: "(x4)"}, {Text: "Still a line"}}},
+			}},
+		},
+		{
+			name: "should not take parentheses and colons in lyrics for labels",
+			text: "(Oh yeah)\nListen:",
+			want: Lyrics{Stanzas: []Stanza{
+				{Lines: []Line{{Text: "(Oh yeah)"}, {Text: "Listen:"}}},
+			}},
+		},
+		{
+			name: "should return no stanzas for empty lyrics",
+			text: "\n \n",
+			want: Lyrics{},
+		},
+	}
+	for _, tt := range tests {
+		if got := ParseLyrics(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. ParseLyrics() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLyrics_String(t *testing.T) {
+	tests := []struct {
+		name   string
+		lyrics Lyrics
+		want   string
+	}{
+		{
+			name:   "should reproduce parsed lyrics",
+			lyrics: ParseLyrics("[Verse 1]\nOne (x2)\n[Chorus]\nTwo\n[x3]\n\nThree"),
+			want:   "[Verse 1]\nOne (x2)\n[Chorus]\nTwo\n[x3]\n\nThree",
+		},
+		{
+			name: "should write the labels and counts of built lyrics",
+			lyrics: Lyrics{Stanzas: []Stanza{
+				{Label: "Chorus", Repeat: 2, Lines: []Line{{Text: "Hey", Repeat: 3}, {Text: "Ho"}}},
+				{Lines: []Line{{Text: "Bye"}}},
+			}},
+			want: "[Chorus]\nHey (x3)\nHo\n(x2)\n\nBye",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.lyrics.String(); got != tt.want {
+			t.Errorf("%q. Lyrics.String() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLyrics_StringEdited(t *testing.T) {
+	text := "[Chorus x2]\nHey (x2)\nHo [3x]\n\nBye\n(x2)"
+	tests := []struct {
+		name string
+		edit func(lyrics *Lyrics)
+		want string
+	}{
+		{
+			name: "should keep the labels and counts as written when unchanged",
+			edit: func(lyrics *Lyrics) {},
+			want: text,
+		},
+		{
+			name: "should write edited labels, stanza counts and line counts",
+			edit: func(lyrics *Lyrics) {
+				lyrics.Stanzas[0].Label = "Verse 1"
+				lyrics.Stanzas[0].Repeat = 0
+				lyrics.Stanzas[0].Lines[0].Repeat = 5
+			},
+			want: "[Verse 1]\nHey (x5)\nHo [3x]\n\nBye\n(x2)",
+		},
+		{
+			name: "should write edited counts of labelled stanzas",
+			edit: func(lyrics *Lyrics) {
+				lyrics.Stanzas[0].Repeat = 4
+				lyrics.Stanzas[0].Lines[1].Repeat = 0
+			},
+			want: "[Chorus]\nHey (x2)\nHo\n(x4)\n\nBye\n(x2)",
+		},
+		{
+			name: "should write edited counts of stanzas ending with one",
+			edit: func(lyrics *Lyrics) {
+				lyrics.Stanzas[1].Repeat = 3
+			},
+			want: "[Chorus x2]\nHey (x2)\nHo [3x]\n\nBye\n(x3)",
+		},
+		{
+			name: "should label stanzas parsed without a label",
+			edit: func(lyrics *Lyrics) {
+				lyrics.Stanzas[1].Label = "Outro"
+			},
+			want: "[Chorus x2]\nHey (x2)\nHo [3x]\n\n[Outro]\nBye\n(x2)",
+		},
+	}
+	for _, tt := range tests {
+		lyrics := ParseLyrics(text)
+		tt.edit(&lyrics)
+		if got := lyrics.String(); got != tt.want {
+			t.Errorf("%q. Lyrics.String() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLyrics_Lines(t *testing.T) {
+	lyrics := ParseLyrics("[Verse]\nOne\n\nTwo\nThree")
+	want := []Line{{Text: "One"}, {Text: "Two"}, {Text: "Three"}}
+	if got := lyrics.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lyrics.Lines() = %+v, want %+v", got, want)
+	}
+}
+
+func TestTrack_ParsedLyrics(t *testing.T) {
+	track := Track{Lyrics: "Pain\nCan't run"}
+	if got := track.ParsedLyrics().String(); got != track.Lyrics {
+		t.Errorf("Track.ParsedLyrics().String() = %q, want %q", got, track.Lyrics)
+	}
+}
@@ -0,0 +1,295 @@
+package golyrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/mamal72/golyrics/wikitext"
+)
+
+const mediawikiProviderName = "mediawiki"
+
+const apiURI = "http://lyrics.wikia.com/api.php"
+
+// maxSearchResults is the number of pages asked for by a search.
+const maxSearchResults = 50
+
+var apiContentTypes = []string{"application/json"}
+
+var (
+	// disambiguationPattern matches the template and category
+	// marking the wikitext of a disambiguation page.
+	disambiguationPattern = regexp.MustCompile(`(?i)\{\{\s*disambig|\[\[\s*Category\s*:\s*Disambiguation`)
+	// wikiLinkPattern matches the target of a wiki link.
+	wikiLinkPattern = regexp.MustCompile(`\[\[\s*([^\]|#]+)`)
+)
+
+// mediawikiProvider reads the wikitext of lyrics pages through the
+// api.php endpoint of a MediaWiki site, instead of scraping rendered pages.
+type mediawikiProvider struct {
+	client     *Client
+	apiURI     string
+	formatting bool
+}
+
+func newMediaWikiProvider(c *Client) Provider {
+	return &mediawikiProvider{
+		client:     c,
+		apiURI:     c.apiURI,
+		formatting: c.formatting,
+	}
+}
+
+func (p *mediawikiProvider) Name() string {
+	return mediawikiProviderName
+}
+
+func (p *mediawikiProvider) cacheID() string {
+	return fmt.Sprintf("%s(%s,%t)", mediawikiProviderName, p.apiURI, p.formatting)
+}
+
+func (p *mediawikiProvider) Search(ctx context.Context, query string) ([]Track, error) {
+	URI := p.apiURI + "?" + url.Values{
+		"action":      {"query"},
+		"list":        {"search"},
+		"srsearch":    {query},
+		"srnamespace": {"0"},
+		"srlimit":     {fmt.Sprint(maxSearchResults)},
+		"format":      {"json"},
+	}.Encode()
+	data, err := p.query(ctx, URI)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks := []Track{}
+	var parseErr error
+	err = jsonparser.ArrayEach(data, func(value []byte, _ jsonparser.ValueType, _ int, err error) {
+		if err != nil {
+			parseErr = err
+		}
+		if parseErr != nil {
+			return
+		}
+		title, err := jsonparser.GetString(value, "title")
+		if err != nil {
+			parseErr = err
+			return
+		}
+		if artist, name, ok := splitTitle(normalizeWikiTitle(title)); ok {
+			tracks = append(tracks, Track{Artist: artist, Name: name})
+		}
+	}, "query", "search")
+	if err == nil {
+		err = parseErr
+	}
+	if err != nil {
+		return nil, &ParseError{URL: URI, Err: err}
+	}
+	return tracks, nil
+}
+
+func (p *mediawikiProvider) Fetch(ctx context.Context, track *Track) error {
+	requested := wikiTitle(track.Artist, track.Name)
+	URI := p.apiURI + "?" + url.Values{
+		"action":        {"query"},
+		"prop":          {"revisions|info"},
+		"rvprop":        {"content"},
+		"inprop":        {"url"},
+		"rvslots":       {"main"},
+		"redirects":     {"1"},
+		"titles":        {requested},
+		"format":        {"json"},
+		"formatversion": {"2"},
+	}.Encode()
+	data, err := p.query(ctx, URI)
+	if err != nil {
+		return err
+	}
+	title, source, pageURL, err := pageContent(data)
+	if err != nil {
+		return &ParseError{URL: URI, Err: err}
+	}
+	if source == "" {
+		return ErrNotFound
+	}
+	title = normalizeWikiTitle(title)
+
+	if disambiguationPattern.MatchString(source) {
+		return &AmbiguousError{Title: title, Candidates: wikitextTracks(source, title)}
+	}
+	page := wikitext.Parse(source)
+	if page.Instrumental && len(page.Stanzas) == 0 {
+		return ErrInstrumental
+	}
+	if err := classifyLyrics(page.Text(), true); err != nil {
+		return err
+	}
+	lyrics := pageLyrics(page, p.formatting)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if title != requested {
+		if artist, name, ok := splitTitle(title); ok {
+			track.Artist, track.Name = artist, name
+		}
+	}
+	track.Lyrics = lyrics
+	track.URL = pageURL
+	setMetadata(track, page.Metadata)
+	return nil
+}
+
+// setMetadata sets the metadata of track found in the song templates.
+func setMetadata(track *Track, m wikitext.Metadata) {
+	if m.Album != "" {
+		track.Album = m.Album
+	}
+	if m.Year != 0 {
+		track.Year = m.Year
+	}
+	if m.TrackNumber != 0 {
+		track.TrackNumber = m.TrackNumber
+	}
+	if m.Language != "" {
+		track.Language = m.Language
+	}
+	for _, name := range m.Featuring {
+		track.addFeaturing(name)
+	}
+	for _, name := range m.Writers {
+		track.addWriter(name)
+	}
+}
+
+// query sends an API request and returns its response,
+// failing with the error the API reported, if any.
+func (p *mediawikiProvider) query(ctx context.Context, URI string) ([]byte, error) {
+	data, err := p.client.get(ctx, URI, apiContentTypes...)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	code, err := jsonparser.GetString(data, "error", "code")
+	if err != nil {
+		return data, nil
+	}
+	info, _ := jsonparser.GetString(data, "error", "info")
+	switch code {
+	case "ratelimited":
+		return nil, fmt.Errorf("%w: %s", ErrRateLimited, info)
+	case "maxlag", "readonly", "internal_api_error":
+		return nil, fmt.Errorf("%w: %s", ErrUpstream, info)
+	}
+	return nil, &ParseError{URL: URI, Err: fmt.Errorf("%s: %s", code, info)}
+}
+
+// pageContent returns the title, wikitext and URL of the first page of a
+// prop=revisions|info response, or an empty wikitext if the page is missing.
+// Pages are a list with formatversion=2, and an object keyed by page ID
+// on older wikis, which also keep the content in a "*" field.
+func pageContent(data []byte) (title, source, URL string, err error) {
+	pages, kind, _, err := jsonparser.Get(data, "query", "pages")
+	if err != nil {
+		return "", "", "", err
+	}
+	var page []byte
+	switch kind {
+	case jsonparser.Array:
+		err = jsonparser.ArrayEach(pages, func(value []byte, _ jsonparser.ValueType, _ int, _ error) {
+			if page == nil {
+				page = value
+			}
+		})
+	case jsonparser.Object:
+		err = jsonparser.ObjectEach(pages, func(_ []byte, value []byte, _ jsonparser.ValueType, _ int) error {
+			if page == nil {
+				page = value
+			}
+			return nil
+		})
+	default:
+		err = errors.New("pages is not a list")
+	}
+	if err != nil {
+		return "", "", "", err
+	}
+	if page == nil {
+		return "", "", "", errors.New("no pages")
+	}
+
+	if title, err = jsonparser.GetString(page, "title"); err != nil {
+		return "", "", "", err
+	}
+	URL, _ = jsonparser.GetString(page, "fullurl")
+	var revision []byte
+	err = jsonparser.ArrayEach(page, func(value []byte, _ jsonparser.ValueType, _ int, _ error) {
+		if revision == nil {
+			revision = value
+		}
+	}, "revisions")
+	if err != nil || revision == nil {
+		// Missing pages have no revisions.
+		return title, "", URL, nil
+	}
+	for _, keys := range [][]string{{"slots", "main", "content"}, {"content"}, {"*"}} {
+		if source, err = jsonparser.GetString(revision, keys...); err == nil {
+			return title, source, URL, nil
+		}
+	}
+	return "", "", "", errors.New("revision has no content")
+}
+
+// pageLyrics returns the lyrics of page, with its italic and bold text
+// in <i> and <b> tags and the rest escaped when formatting.
+func pageLyrics(page wikitext.Page, formatting bool) string {
+	if !formatting {
+		return page.Text()
+	}
+	stanzas := make([]string, len(page.Stanzas))
+	for i, stanza := range page.Stanzas {
+		lines := make([]string, len(stanza))
+		for j, line := range stanza {
+			var text strings.Builder
+			for _, segment := range line {
+				open, close := "", ""
+				if segment.Bold {
+					open, close = "<b>", "</b>"
+				}
+				if segment.Italic {
+					open, close = open+"<i>", "</i>"+close
+				}
+				text.WriteString(open + html.EscapeString(segment.Text) + close)
+			}
+			lines[j] = text.String()
+		}
+		stanzas[i] = strings.Join(lines, "\n")
+	}
+	return strings.Join(stanzas, "\n\n")
+}
+
+// wikitextTracks returns the songs linked from the wikitext source,
+// other than the page titled self.
+func wikitextTracks(source, self string) []Track {
+	tracks := []Track{}
+	seen := map[string]bool{self: true}
+	for _, match := range wikiLinkPattern.FindAllStringSubmatch(source, -1) {
+		title := normalizeWikiTitle(match[1])
+		artist, name, ok := splitTitle(title)
+		if !ok || seen[title] {
+			continue
+		}
+		seen[title] = true
+		tracks = append(tracks, Track{Artist: artist, Name: name})
+	}
+	return tracks
+}
@@ -0,0 +1,222 @@
+package golyrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/mamal72/golyrics/wikitext"
+)
+
+func newMediaWikiTestServer(t *testing.T) *httptest.Server {
+	pages := map[string]string{
+		"Blackfield:Pain": `{"query":{"pages":[{"pageid":1,"title":"Blackfield:Pain","fullurl":"http://wiki/wiki/Blackfield:Pain",` +
+			`"revisions":[{"slots":{"main":{"content":"{{Song|Blackfield (2004)|Blackfield}}\n<lyrics>\nPain\nCan't run\n</lyrics>\n` +
+			`<lyrics>Again</lyrics>\n{{SongFooter|track=3|featuring=Aviv Geffen|music=Steven Wilson, Aviv Geffen|language=English}}"}}}]}]}}`,
+		"Old:Name": `{"query":{"redirects":[{"from":"Old:Name","to":"Blackfield:Pain"}],"pages":[{"pageid":1,"title":"Blackfield:Pain",` +
+			`"revisions":[{"slots":{"main":{"content":"<lyrics>Pain</lyrics>"}}}]}]}}`,
+		"Legacy:Wiki":  `{"query":{"pages":{"7":{"pageid":7,"title":"Legacy:Wiki","revisions":[{"*":"<lyrics>Old\nschool</lyrics>"}]}}}}`,
+		"Missing:Page": `{"query":{"pages":[{"ns":0,"title":"Missing:Page","missing":true}]}}`,
+		"Stub:Page":    `{"query":{"pages":[{"title":"Stub:Page","revisions":[{"slots":{"main":{"content":"{{Song|Stub}}\n<lyrics>\n</lyrics>"}}}]}]}}`,
+		"Takk:Intro":   `{"query":{"pages":[{"title":"Takk:Intro","revisions":[{"slots":{"main":{"content":"{{Song|Takk... (2005)|Takk}}\n<lyrics>\n{{Instrumental}}\n</lyrics>"}}}]}]}}`,
+		"Pain:Songs": `{"query":{"pages":[{"title":"Pain:Songs","revisions":[{"slots":{"main":{"content":` +
+			`"{{Disambig}}\n* [[Blackfield:Pain|Blackfield]]\n* [[Three Days Grace:Pain]]\n* [[Blackfield:Pain]]\n[[Category:Disambiguation]]"}}}]}]}}`,
+		"Busy:Wiki":   `{"error":{"code":"ratelimited","info":"You've exceeded your rate limit."}}`,
+		"Broken:Wiki": `{"query":{"pages":"nope"}}`,
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api.php" || r.URL.Query().Get("format") != "json" {
+			t.Errorf("unexpected API request %s", r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		query := r.URL.Query()
+		if query.Get("list") == "search" {
+			switch query.Get("srsearch") {
+			case "pain":
+				fmt.Fprint(w, `{"query":{"search":[{"ns":0,"title":"Blackfield:Pain"},{"ns":0,"title":"Blackfield"},{"ns":0,"title":"Three Days Grace:Pain"}]}}`)
+			default:
+				fmt.Fprint(w, `{"query":{"search":[]}}`)
+			}
+			return
+		}
+		if query.Get("prop") != "revisions|info" || query.Get("inprop") != "url" || query.Get("redirects") != "1" {
+			t.Errorf("unexpected API request %s", r.URL)
+		}
+		page, ok := pages[query.Get("titles")]
+		if !ok {
+			page = fmt.Sprintf(`{"query":{"pages":[{"title":%q,"missing":true}]}}`, query.Get("titles"))
+		}
+		fmt.Fprint(w, page)
+	}))
+}
+
+func TestMediaWikiProvider_Search(t *testing.T) {
+	server := newMediaWikiTestServer(t)
+	defer server.Close()
+	provider := newMediaWikiProvider(NewClient(WithAPIURI(server.URL + "/api.php")))
+
+	tests := []struct {
+		name  string
+		query string
+		want  []Track
+	}{
+		{
+			name:  "should return the results that are songs",
+			query: "pain",
+			want: []Track{
+				{Artist: "Blackfield", Name: "Pain"},
+				{Artist: "Three Days Grace", Name: "Pain"},
+			},
+		},
+		{
+			name:  "should return no tracks without results",
+			query: "nothing",
+			want:  []Track{},
+		},
+	}
+	for _, tt := range tests {
+		got, err := provider.Search(context.Background(), tt.query)
+		if err != nil {
+			t.Errorf("%q. mediawikiProvider.Search() error = %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. mediawikiProvider.Search() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMediaWikiProvider_Fetch(t *testing.T) {
+	server := newMediaWikiTestServer(t)
+	defer server.Close()
+	provider := newMediaWikiProvider(NewClient(
+		WithAPIURI(server.URL+"/api.php"),
+		WithRetryPolicy(RetryPolicy{}),
+	))
+
+	tests := []struct {
+		name    string
+		track   Track
+		want    Track
+		wantErr error
+	}{
+		{
+			name:  "should extract the lyrics blocks and metadata from the wikitext",
+			track: Track{Artist: "blackfield", Name: "pain"},
+			want: Track{
+				Artist:      "blackfield",
+				Name:        "pain",
+				Lyrics:      "Pain\nCan't run\n\nAgain",
+				Album:       "Blackfield",
+				Year:        2004,
+				TrackNumber: 3,
+				Metadata: &TrackMetadata{
+					Featuring: []string{"Aviv Geffen"},
+					Writers:   []string{"Steven Wilson", "Aviv Geffen"},
+				},
+				Language: "English",
+				URL:      "http://wiki/wiki/Blackfield:Pain",
+			},
+		},
+		{
+			name:  "should update the track with the page redirected to",
+			track: Track{Artist: "Old", Name: "Name"},
+			want:  Track{Artist: "Blackfield", Name: "Pain", Lyrics: "Pain"},
+		},
+		{
+			name:  "should read responses of wikis without formatversion=2",
+			track: Track{Artist: "Legacy", Name: "Wiki"},
+			want:  Track{Artist: "Legacy", Name: "Wiki", Lyrics: "Old\nschool"},
+		},
+		{
+			name:    "should fail as not found for missing pages",
+			track:   Track{Artist: "Missing", Name: "Page"},
+			want:    Track{Artist: "Missing", Name: "Page"},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "should fail as a stub for empty lyrics blocks",
+			track:   Track{Artist: "Stub", Name: "Page"},
+			want:    Track{Artist: "Stub", Name: "Page"},
+			wantErr: ErrStub,
+		},
+		{
+			name:    "should fail as instrumental for instrumental markers",
+			track:   Track{Artist: "Takk", Name: "Intro"},
+			want:    Track{Artist: "Takk", Name: "Intro"},
+			wantErr: ErrInstrumental,
+		},
+		{
+			name:    "should fail as ambiguous for disambiguation pages",
+			track:   Track{Artist: "Pain", Name: "Songs"},
+			want:    Track{Artist: "Pain", Name: "Songs"},
+			wantErr: ErrAmbiguous,
+		},
+		{
+			name:    "should fail as rate limited when the API says so",
+			track:   Track{Artist: "Busy", Name: "Wiki"},
+			want:    Track{Artist: "Busy", Name: "Wiki"},
+			wantErr: ErrRateLimited,
+		},
+		{
+			name:    "should fail with a parse error for malformed responses",
+			track:   Track{Artist: "Broken", Name: "Wiki"},
+			want:    Track{Artist: "Broken", Name: "Wiki"},
+			wantErr: ErrParse,
+		},
+	}
+	for _, tt := range tests {
+		track := tt.track
+		if err := provider.Fetch(context.Background(), &track); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%q. mediawikiProvider.Fetch() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(track, tt.want) {
+			t.Errorf("%q. mediawikiProvider.Fetch() track = %+v, want %+v", tt.name, track, tt.want)
+		}
+	}
+}
+
+func Test_wikitextTracks(t *testing.T) {
+	wikitext := "* [[Blackfield:Pain|Blackfield]]\n* [[three Days Grace:Pain#Lyrics]]\n* [[Pain:Songs]]\n[[Category:Disambiguation]]"
+	want := []Track{
+		{Artist: "Blackfield", Name: "Pain"},
+		{Artist: "Three Days Grace", Name: "Pain"},
+	}
+	if got := wikitextTracks(wikitext, "Pain:Songs"); !reflect.DeepEqual(got, want) {
+		t.Errorf("wikitextTracks() = %v, want %v", got, want)
+	}
+}
+
+func Test_pageLyrics(t *testing.T) {
+	page := wikitext.Page{
+		Stanzas: []wikitext.Stanza{
+			{{{Text: "Salt & "}, {Text: "pepper", Italic: true}}, {{Text: "Now", Italic: true, Bold: true}}},
+			{{{Text: "Again", Bold: true}}},
+		},
+	}
+	tests := []struct {
+		name       string
+		formatting bool
+		want       string
+	}{
+		{
+			name: "should return plain text by default",
+			want: "Salt & pepper\nNow\n\nAgain",
+		},
+		{
+			name:       "should keep italic and bold text with formatting",
+			formatting: true,
+			want:       "Salt &amp; <i>pepper</i>\n<b><i>Now</i></b>\n\n<b>Again</b>",
+		},
+	}
+	for _, tt := range tests {
+		if got := pageLyrics(page, tt.formatting); got != tt.want {
+			t.Errorf("%q. pageLyrics() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
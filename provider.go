@@ -0,0 +1,97 @@
+package golyrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Provider is a source of tracks and lyrics.
+type Provider interface {
+	// Name returns the name of the provider, as used in the registry.
+	Name() string
+	// Search searches for tracks
+	// using a string query that can be part of the track name or artist.
+	Search(ctx context.Context, query string) ([]Track, error)
+	// Fetch fetches the lyrics of a Track and sets it on that track.
+	Fetch(ctx context.Context, track *Track) error
+}
+
+// ProviderFactory creates a Provider that sends its HTTP requests
+// through the given Client, see Client.Do.
+type ProviderFactory func(c *Client) Provider
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]ProviderFactory{
+		wikiaProviderName:     newWikiaProvider,
+		mediawikiProviderName: newMediaWikiProvider,
+	}
+)
+
+// RegisterProvider makes a provider available by name to WithProvider.
+// It panics if factory is nil or if a provider is already registered under name.
+func RegisterProvider(name string, factory ProviderFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if factory == nil {
+		panic("golyrics: RegisterProvider factory is nil")
+	}
+	if _, dup := providers[name]; dup {
+		panic("golyrics: RegisterProvider called twice for provider " + name)
+	}
+	providers[name] = factory
+}
+
+// Providers returns the sorted names of the registered providers.
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewProvider creates the provider registered under name,
+// sending its requests through c.
+func NewProvider(name string, c *Client) (Provider, error) {
+	providersMu.RLock()
+	factory, ok := providers[name]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("golyrics: unknown provider %q", name)
+	}
+	return factory(c), nil
+}
+
+// newProvider is like NewProvider but returns a Provider that
+// fails every lookup with the error for an unknown name,
+// so that NewClient does not need to return an error.
+func newProvider(name string, c *Client) Provider {
+	provider, err := NewProvider(name, c)
+	if err != nil {
+		return brokenProvider{name: name, err: err}
+	}
+	return provider
+}
+
+type brokenProvider struct {
+	name string
+	err  error
+}
+
+func (p brokenProvider) Name() string {
+	return p.name
+}
+
+func (p brokenProvider) Search(ctx context.Context, query string) ([]Track, error) {
+	return nil, p.err
+}
+
+func (p brokenProvider) Fetch(ctx context.Context, track *Track) error {
+	return p.err
+}
@@ -0,0 +1,110 @@
+package golyrics
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type staticProvider struct {
+	name   string
+	tracks []Track
+	lyrics string
+	err    error
+}
+
+func (p *staticProvider) Name() string {
+	return p.name
+}
+
+func (p *staticProvider) Search(ctx context.Context, query string) ([]Track, error) {
+	return p.tracks, p.err
+}
+
+func (p *staticProvider) Fetch(ctx context.Context, track *Track) error {
+	if p.err != nil {
+		return p.err
+	}
+	track.Lyrics = p.lyrics
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// unregisterProvider removes the provider registered under name,
+// so that tests registering providers can run again in the same process.
+func unregisterProvider(name string) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	delete(providers, name)
+}
+
+func TestRegisterProvider(t *testing.T) {
+	RegisterProvider("static-test", func(c *Client) Provider {
+		return &staticProvider{name: "static-test", lyrics: "registered"}
+	})
+	defer unregisterProvider("static-test")
+
+	if got := Providers(); !sort.StringsAreSorted(got) || !containsString(got, "static-test") || !containsString(got, "wikia") {
+		t.Errorf("Providers() = %v, want sorted names including static-test and wikia", got)
+	}
+
+	tests := []struct {
+		name       string
+		provider   string
+		wantLyrics string
+		wantErr    bool
+	}{
+		{
+			name:       "should use the provider registered under the name",
+			provider:   "static-test",
+			wantLyrics: "registered",
+		},
+		{
+			name:     "should fail lookups for unknown providers",
+			provider: "missing",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		client := NewClient(WithProvider(tt.provider))
+		track := Track{Artist: "Blackfield", Name: "Pain"}
+		if err := client.Fetch(&track); (err != nil) != tt.wantErr {
+			t.Errorf("%q. Client.Fetch() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if track.Lyrics != tt.wantLyrics {
+			t.Errorf("%q. Client.Fetch() lyrics = %q, want %q", tt.name, track.Lyrics, tt.wantLyrics)
+		}
+		if _, err := NewProvider(tt.provider, client); (err != nil) != tt.wantErr {
+			t.Errorf("%q. NewProvider() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("RegisterProvider() did not panic for a duplicate name")
+		}
+	}()
+	RegisterProvider("wikia", newWikiaProvider)
+}
+
+func TestWithProviderInstance(t *testing.T) {
+	provider := &staticProvider{name: "static", tracks: []Track{{Artist: "A", Name: "B"}}}
+	client := NewClient(WithProviderInstance(provider))
+	if client.Provider() != provider {
+		t.Errorf("Client.Provider() = %v, want %v", client.Provider(), provider)
+	}
+	got, err := client.Search("a:b")
+	if err != nil || !reflect.DeepEqual(got, provider.tracks) {
+		t.Errorf("Client.Search() = %v, %v, want %v, nil", got, err, provider.tracks)
+	}
+}
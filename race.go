@@ -0,0 +1,290 @@
+package golyrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const raceProviderName = "race"
+
+// DefaultThreshold is the score at which a Race accepts an answer
+// without waiting for the other providers.
+const DefaultThreshold = 0.8
+
+// completeLyricsLines is the number of lines from which DefaultScorer
+// considers lyrics complete rather than a fragment or a stub.
+const completeLyricsLines = 8
+
+// Scorer rates from 0 to 1 how well candidate answers a lookup for wanted.
+type Scorer func(wanted, candidate Track) float64
+
+// DefaultScorer scores candidates with no lyrics 0, and others by how closely
+// their artist and name match the wanted ones and by how long their lyrics are.
+func DefaultScorer(wanted, candidate Track) float64 {
+	lyrics := strings.TrimSpace(candidate.Lyrics)
+	if lyrics == "" {
+		return 0
+	}
+	lines := strings.Count(lyrics, "\n") + 1
+	completeness := math.Min(float64(lines)/completeLyricsLines, 1)
+	return 0.3*similarity(wanted.Artist, candidate.Artist) +
+		0.3*similarity(wanted.Name, candidate.Name) +
+		0.4*completeness
+}
+
+// similarity is 1 for strings equal when ignoring case, spaces and punctuation,
+// 0.5 when one contains the other and 0 otherwise.
+// An empty wanted string matches anything.
+func similarity(wanted, got string) float64 {
+	wanted, got = comparable(wanted), comparable(got)
+	switch {
+	case wanted == "" || wanted == got:
+		return 1
+	case got != "" && (strings.Contains(got, wanted) || strings.Contains(wanted, got)):
+		return 0.5
+	}
+	return 0
+}
+
+func comparable(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
+
+// RaceConfig tunes a Race.
+type RaceConfig struct {
+	// Scorer rates the answers. DefaultScorer is used when nil.
+	Scorer Scorer
+	// Threshold is the score at which an answer is accepted at once and the
+	// remaining providers are cancelled. DefaultThreshold is used when zero.
+	Threshold float64
+	// Deadline is how long to wait for an answer reaching Threshold before
+	// settling for the best one so far. Zero waits for every provider.
+	Deadline time.Duration
+}
+
+// Race is a Provider that queries all its providers concurrently
+// and keeps the best answer.
+type Race struct {
+	config    RaceConfig
+	providers []Provider
+
+	// after, when set, replaces the Deadline timer, and received, when set,
+	// is called with every answer the race got. Tests set them to end races
+	// once given answers were received.
+	after    func(d time.Duration) <-chan time.Time
+	received func(provider string)
+}
+
+// NewRace creates a Race over providers.
+func NewRace(config RaceConfig, providers ...Provider) *Race {
+	if config.Scorer == nil {
+		config.Scorer = DefaultScorer
+	}
+	if config.Threshold == 0 {
+		config.Threshold = DefaultThreshold
+	}
+	return &Race{config: config, providers: providers}
+}
+
+// Name returns "race".
+func (r *Race) Name() string {
+	return raceProviderName
+}
+
+func (r *Race) cacheID() string {
+	return cacheIDs(raceProviderName, r.providers)
+}
+
+// RaceDeadlineError is returned by a Race when its Deadline passed
+// before any provider answered. It matches context.DeadlineExceeded
+// with errors.Is.
+type RaceDeadlineError struct {
+	// Pending names the providers that had not answered yet.
+	Pending []string
+	// Failures holds why the providers that answered failed.
+	Failures []ProviderError
+}
+
+func (e *RaceDeadlineError) Error() string {
+	message := fmt.Sprintf("golyrics: race deadline passed waiting for %s", strings.Join(e.Pending, ", "))
+	if len(e.Failures) == 0 {
+		return message
+	}
+	reasons := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		reasons[i] = failure.Error()
+	}
+	return fmt.Sprintf("%s; failed: %s", message, strings.Join(reasons, "; "))
+}
+
+// Unwrap returns context.DeadlineExceeded.
+func (e *RaceDeadlineError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+type raceAnswer struct {
+	index    int
+	provider Provider
+	tracks   []Track
+	track    Track
+	err      error
+}
+
+// run calls lookup for every provider concurrently and passes each answer
+// to accept until accept returns true, every provider answered,
+// the deadline passed or ctx is done. When the deadline passed,
+// it returns the names of the providers that had not answered.
+func (r *Race) run(ctx context.Context, lookup func(context.Context, Provider) raceAnswer, accept func(raceAnswer) bool) ([]string, error) {
+	raceCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	answers := make(chan raceAnswer, len(r.providers))
+	for i, provider := range r.providers {
+		go func(i int, provider Provider) {
+			answer := lookup(raceCtx, provider)
+			answer.index = i
+			answers <- answer
+		}(i, provider)
+	}
+	answered := make([]bool, len(r.providers))
+
+	var deadline <-chan time.Time
+	switch {
+	case r.config.Deadline > 0 && r.after != nil:
+		deadline = r.after(r.config.Deadline)
+	case r.config.Deadline > 0:
+		timer := time.NewTimer(r.config.Deadline)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	for range r.providers {
+		select {
+		case answer := <-answers:
+			answered[answer.index] = true
+			if r.received != nil {
+				r.received(answer.provider.Name())
+			}
+			if accept(answer) {
+				return nil, nil
+			}
+		case <-deadline:
+			var pending []string
+			for i, provider := range r.providers {
+				if !answered[i] {
+					pending = append(pending, provider.Name())
+				}
+			}
+			return pending, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return nil, nil
+}
+
+// Search returns the tracks found by all providers, without duplicates,
+// in the order the providers answered. It fails with a *RaceDeadlineError
+// when the deadline passed before any provider answered.
+func (r *Race) Search(ctx context.Context, query string) ([]Track, error) {
+	var failures []ProviderError
+	seen := map[Track]bool{}
+	suggestions := []Track{}
+	answered := false
+	lookup := func(ctx context.Context, provider Provider) raceAnswer {
+		tracks, err := provider.Search(ctx, query)
+		return raceAnswer{provider: provider, tracks: tracks, err: err}
+	}
+	pending, err := r.run(ctx, lookup, func(answer raceAnswer) bool {
+		if answer.err != nil {
+			failures = append(failures, ProviderError{Provider: answer.provider.Name(), Err: answer.err})
+			return false
+		}
+		answered = true
+		for _, track := range answer.tracks {
+			key := Track{Artist: comparable(track.Artist), Name: comparable(track.Name)}
+			if !seen[key] {
+				seen[key] = true
+				suggestions = append(suggestions, track)
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) > 0 && !answered {
+		return nil, &RaceDeadlineError{Pending: pending, Failures: failures}
+	}
+	if len(failures) == len(r.providers) {
+		return nil, &ChainError{Failures: failures}
+	}
+	return suggestions, nil
+}
+
+// Fetch sets the lyrics of track to the best answer of the providers.
+func (r *Race) Fetch(ctx context.Context, track *Track) error {
+	_, err := r.FetchResult(ctx, track)
+	return err
+}
+
+// FetchResult is like Fetch but also reports which provider answered,
+// the score of its answer and why the providers that answered before failed.
+// If no provider answers, the error is a *ChainError,
+// or a *RaceDeadlineError when the deadline passed first.
+func (r *Race) FetchResult(ctx context.Context, track *Track) (*Result, error) {
+	result := &Result{}
+	var best *raceAnswer
+	var notModified error
+	// Stragglers may still start after the race ended, so they read
+	// a copy of track rather than the track the winner is written to.
+	wanted := track.clone()
+	lookup := func(ctx context.Context, provider Provider) raceAnswer {
+		candidate := wanted.clone()
+		err := provider.Fetch(ctx, &candidate)
+		if err == nil && candidate.Lyrics == "" {
+			err = ErrNotFound
+		}
+		return raceAnswer{provider: provider, track: candidate, err: err}
+	}
+	pending, err := r.run(ctx, lookup, func(answer raceAnswer) bool {
+		if errors.Is(answer.err, ErrNotModified) {
+			notModified = answer.err
+			return true
+		}
+		if answer.err != nil {
+			result.Failures = append(result.Failures, ProviderError{Provider: answer.provider.Name(), Err: answer.err})
+			return false
+		}
+		score := r.config.Scorer(wanted, answer.track)
+		if best == nil || score > result.Score {
+			best = &answer
+			result.Score = score
+		}
+		return score >= r.config.Threshold
+	})
+	if err == nil {
+		err = notModified
+	}
+	if err != nil {
+		return nil, err
+	}
+	if best == nil && len(pending) > 0 {
+		return nil, &RaceDeadlineError{Pending: pending, Failures: result.Failures}
+	}
+	if best == nil {
+		return nil, &ChainError{Failures: result.Failures}
+	}
+	*track = best.track
+	result.Provider = best.provider.Name()
+	return result, nil
+}
@@ -0,0 +1,257 @@
+package golyrics
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// slowProvider answers once release is closed, never when it is nil,
+// or fails when its context is done first.
+type slowProvider struct {
+	staticProvider
+	release   chan struct{}
+	cancelled chan struct{}
+}
+
+func (p *slowProvider) wait(ctx context.Context) error {
+	select {
+	case <-p.release:
+		return nil
+	case <-ctx.Done():
+		if p.cancelled != nil {
+			close(p.cancelled)
+		}
+		return ctx.Err()
+	}
+}
+
+func (p *slowProvider) Search(ctx context.Context, query string) ([]Track, error) {
+	if err := p.wait(ctx); err != nil {
+		return nil, err
+	}
+	return p.staticProvider.Search(ctx, query)
+}
+
+func (p *slowProvider) Fetch(ctx context.Context, track *Track) error {
+	if err := p.wait(ctx); err != nil {
+		return err
+	}
+	return p.staticProvider.Fetch(ctx, track)
+}
+
+// endRaceAfter makes the deadline of race pass
+// as soon as it received the answer of the provider named name.
+func endRaceAfter(race *Race, name string) {
+	var deadline chan time.Time
+	race.after = func(time.Duration) <-chan time.Time {
+		deadline = make(chan time.Time)
+		return deadline
+	}
+	race.received = func(provider string) {
+		if provider == name {
+			close(deadline)
+		}
+	}
+}
+
+func TestDefaultScorer(t *testing.T) {
+	wanted := Track{Artist: "Blackfield", Name: "End of the World"}
+	tests := []struct {
+		name      string
+		candidate Track
+		want      float64
+	}{
+		{
+			name:      "should score tracks without lyrics 0",
+			candidate: Track{Artist: "Blackfield", Name: "End of the World"},
+			want:      0,
+		},
+		{
+			name:      "should score matching tracks with complete lyrics 1",
+			candidate: Track{Artist: "blackfield", Name: "End Of The World!", Lyrics: "1\n2\n3\n4\n5\n6\n7\n8"},
+			want:      1,
+		},
+		{
+			name:      "should score partial matches with short lyrics lower",
+			candidate: Track{Artist: "Blackfield", Name: "End", Lyrics: "1\n2"},
+			want:      0.3 + 0.15 + 0.1,
+		},
+	}
+	for _, tt := range tests {
+		if got := DefaultScorer(wanted, tt.candidate); got < tt.want-1e-9 || got > tt.want+1e-9 {
+			t.Errorf("%q. DefaultScorer() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRace_FetchResult(t *testing.T) {
+	blocked := errors.New("blocked")
+	released := make(chan struct{})
+	close(released)
+	lengthScorer := func(wanted, candidate Track) float64 {
+		return float64(len(candidate.Lyrics)) / 10
+	}
+	tests := []struct {
+		name       string
+		config     RaceConfig
+		providers  []Provider
+		endAfter   string
+		want       *Result
+		wantLyrics string
+		wantErr    bool
+	}{
+		{
+			name:   "should accept the first answer reaching the threshold",
+			config: RaceConfig{Scorer: lengthScorer, Threshold: 0.5},
+			providers: []Provider{
+				&slowProvider{staticProvider: staticProvider{name: "slow", lyrics: "slow and long"}},
+				&staticProvider{name: "fast", lyrics: "fast!"},
+			},
+			want:       &Result{Provider: "fast", Score: 0.5},
+			wantLyrics: "fast!",
+		},
+		{
+			name:   "should keep the best answer when all providers answered",
+			config: RaceConfig{Scorer: lengthScorer, Threshold: 5},
+			providers: []Provider{
+				&staticProvider{name: "short", lyrics: "a"},
+				&slowProvider{staticProvider: staticProvider{name: "long", lyrics: "abc"}, release: released},
+				&staticProvider{name: "failing", err: blocked},
+			},
+			want: &Result{
+				Provider: "long",
+				Score:    0.3,
+				Failures: []ProviderError{{Provider: "failing", Err: blocked}},
+			},
+			wantLyrics: "abc",
+		},
+		{
+			name:   "should settle for the best answer at the deadline",
+			config: RaceConfig{Scorer: lengthScorer, Threshold: 5, Deadline: time.Hour},
+			providers: []Provider{
+				&staticProvider{name: "short", lyrics: "ab"},
+				&slowProvider{staticProvider: staticProvider{name: "slow", lyrics: "slow and long"}},
+			},
+			endAfter:   "short",
+			want:       &Result{Provider: "short", Score: 0.2},
+			wantLyrics: "ab",
+		},
+		{
+			name:   "should fail when no provider answers",
+			config: RaceConfig{},
+			providers: []Provider{
+				&staticProvider{name: "empty"},
+				&staticProvider{name: "failing", err: blocked},
+			},
+			wantLyrics: "old",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		race := NewRace(tt.config, tt.providers...)
+		if tt.endAfter != "" {
+			endRaceAfter(race, tt.endAfter)
+		}
+		track := Track{Artist: "Blackfield", Name: "Pain", Lyrics: "old"}
+		got, err := race.FetchResult(context.Background(), &track)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. Race.FetchResult() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. Race.FetchResult() = %+v, want %+v", tt.name, got, tt.want)
+		}
+		if track.Lyrics != tt.wantLyrics {
+			t.Errorf("%q. Race.FetchResult() lyrics = %q, want %q", tt.name, track.Lyrics, tt.wantLyrics)
+		}
+	}
+}
+
+func TestRace_CancelsStragglers(t *testing.T) {
+	straggler := &slowProvider{
+		staticProvider: staticProvider{name: "straggler", lyrics: "late"},
+		cancelled:      make(chan struct{}),
+	}
+	race := NewRace(RaceConfig{}, straggler, &staticProvider{name: "fast", lyrics: "1\n2\n3\n4\n5\n6\n7\n8"})
+	track := Track{}
+	if err := race.Fetch(context.Background(), &track); err != nil {
+		t.Fatalf("Race.Fetch() error = %v", err)
+	}
+	select {
+	case <-straggler.cancelled:
+	case <-time.After(time.Second):
+		t.Errorf("Race.Fetch() did not cancel the straggling provider")
+	}
+}
+
+func TestRace_Search(t *testing.T) {
+	second := make(chan struct{})
+	race := NewRace(RaceConfig{},
+		&staticProvider{name: "first", tracks: []Track{{Artist: "Blackfield", Name: "Pain"}}},
+		&slowProvider{
+			staticProvider: staticProvider{name: "second", tracks: []Track{{Artist: "blackfield", Name: "pain"}, {Artist: "Blackfield", Name: "Once"}}},
+			release:        second,
+		},
+		&staticProvider{name: "failing", err: errors.New("blocked")},
+	)
+	race.received = func(provider string) {
+		if provider == "first" {
+			close(second)
+		}
+	}
+	want := []Track{{Artist: "Blackfield", Name: "Pain"}, {Artist: "Blackfield", Name: "Once"}}
+	got, err := race.Search(context.Background(), "blackfield:pain")
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Race.Search() = %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestRace_FetchResultMetadata(t *testing.T) {
+	metadata := &TrackMetadata{Featuring: []string{"Aviv Geffen"}}
+	track := Track{Artist: "Blackfield", Name: "Pain", Metadata: metadata}
+	race := NewRace(RaceConfig{},
+		&featuringProvider{staticProvider: staticProvider{name: "first", lyrics: "Pain"}, featuring: "Steven Wilson"},
+		&featuringProvider{staticProvider: staticProvider{name: "second", lyrics: "Pain"}, featuring: "Mikael Akerfeldt"},
+	)
+	if _, err := race.FetchResult(context.Background(), &track); err != nil {
+		t.Fatalf("Race.FetchResult() error = %v", err)
+	}
+	if got := track.Metadata.Featuring; len(got) != 2 || got[0] != "Aviv Geffen" {
+		t.Errorf("Race.FetchResult() featuring = %v, want Aviv Geffen and the winner's", got)
+	}
+	if want := []string{"Aviv Geffen"}; !reflect.DeepEqual(metadata.Featuring, want) {
+		t.Errorf("Race.FetchResult() changed the metadata of the track to %v", metadata.Featuring)
+	}
+}
+
+func TestRace_Deadline(t *testing.T) {
+	blocked := errors.New("blocked")
+	race := NewRace(RaceConfig{Deadline: time.Hour},
+		&slowProvider{staticProvider: staticProvider{name: "slow", lyrics: "late"}},
+		&staticProvider{name: "failing", err: blocked},
+		&slowProvider{staticProvider: staticProvider{name: "slower", tracks: []Track{{Name: "late"}}}},
+	)
+	endRaceAfter(race, "failing")
+	want := &RaceDeadlineError{Pending: []string{"slow", "slower"}, Failures: []ProviderError{{Provider: "failing", Err: blocked}}}
+
+	track := Track{Lyrics: "old"}
+	_, err := race.FetchResult(context.Background(), &track)
+	var deadlineErr *RaceDeadlineError
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.As(err, &deadlineErr) || !reflect.DeepEqual(deadlineErr, want) {
+		t.Errorf("Race.FetchResult() error = %#v, want %#v", err, want)
+	}
+	if track.Lyrics != "old" {
+		t.Errorf("Race.FetchResult() lyrics = %q, want %q", track.Lyrics, "old")
+	}
+	if err != nil && err.Error() != "golyrics: race deadline passed waiting for slow, slower; failed: failing: blocked" {
+		t.Errorf("Race.FetchResult() error = %q", err.Error())
+	}
+
+	_, err = race.Search(context.Background(), "late")
+	if !errors.As(err, &deadlineErr) || !reflect.DeepEqual(deadlineErr, want) {
+		t.Errorf("Race.Search() error = %#v, want %#v", err, want)
+	}
+}
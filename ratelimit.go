@@ -0,0 +1,178 @@
+package golyrics
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimit limits the requests a Client sends to a host.
+type RateLimit struct {
+	// RequestsPerSecond is the rate at which requests may be sent.
+	// Zero means no limit.
+	RequestsPerSecond float64
+	// Burst is how many requests may be sent at once after a quiet period.
+	// Values below 1 are treated as 1.
+	Burst int
+	// MaxConcurrent is the maximum number of requests to the host in flight
+	// at the same time, until their response bodies are closed.
+	// Zero means no limit.
+	MaxConcurrent int
+}
+
+// WithRateLimit limits the requests the Client sends to host,
+// given as in a URL, such as "lyrics.wikia.com" or "localhost:8080".
+func WithRateLimit(host string, limit RateLimit) Option {
+	return func(c *Client) {
+		if c.rateLimits == nil {
+			c.rateLimits = map[string]RateLimit{}
+		}
+		c.rateLimits[host] = limit
+	}
+}
+
+// WithDefaultRateLimit limits the requests the Client sends
+// to every host without a limit of its own.
+func WithDefaultRateLimit(limit RateLimit) Option {
+	return func(c *Client) {
+		c.defaultRateLimit = limit
+	}
+}
+
+// rateLimiter holds the limiters of the hosts a Client sent requests to.
+type rateLimiter struct {
+	defaultLimit RateLimit
+	limits       map[string]RateLimit
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimiter
+}
+
+func newRateLimiter(defaultLimit RateLimit, limits map[string]RateLimit) *rateLimiter {
+	return &rateLimiter{
+		defaultLimit: defaultLimit,
+		limits:       limits,
+		hosts:        map[string]*hostLimiter{},
+	}
+}
+
+func (l *rateLimiter) host(host string) *hostLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limiter, ok := l.hosts[host]
+	if !ok {
+		limit, ok := l.limits[host]
+		if !ok {
+			limit = l.defaultLimit
+		}
+		limiter = newHostLimiter(limit, time.Now())
+		l.hosts[host] = limiter
+	}
+	return limiter
+}
+
+// hostLimiter is a token bucket combined with a semaphore.
+type hostLimiter struct {
+	rate  float64
+	burst float64
+	slots chan struct{}
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newHostLimiter(limit RateLimit, now time.Time) *hostLimiter {
+	burst := math.Max(float64(limit.Burst), 1)
+	limiter := &hostLimiter{
+		rate:   limit.RequestsPerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+	if limit.MaxConcurrent > 0 {
+		limiter.slots = make(chan struct{}, limit.MaxConcurrent)
+	}
+	return limiter
+}
+
+// take takes a token if one is available at now,
+// or returns how long to wait for the next one.
+func (l *hostLimiter) take(now time.Time) (time.Duration, bool) {
+	if l.rate <= 0 {
+		return 0, true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0, true
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second)), false
+}
+
+// wait blocks until a request may be sent or ctx is done.
+// When it returns nil, release must be called once the request is over.
+func (l *hostLimiter) wait(ctx context.Context) error {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	for {
+		delay, ok := l.take(time.Now())
+		if ok {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			l.release()
+			return ctx.Err()
+		}
+	}
+}
+
+func (l *hostLimiter) release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// releasingBody releases its request's slot when closed.
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	limiter *hostLimiter
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.limiter.release)
+	return err
+}
+
+// limit waits for the limiter of the request's host, then sends the request
+// with send, holding its slot until the response body is closed.
+func (l *rateLimiter) limit(request *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	limiter := l.host(request.URL.Host)
+	if err := limiter.wait(request.Context()); err != nil {
+		return nil, err
+	}
+	response, err := send(request)
+	if err != nil {
+		limiter.release()
+		return nil, err
+	}
+	response.Body = &releasingBody{ReadCloser: response.Body, limiter: limiter}
+	return response, nil
+}
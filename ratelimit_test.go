@@ -0,0 +1,79 @@
+package golyrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHostLimiter_take(t *testing.T) {
+	start := time.Date(2018, 6, 30, 12, 0, 0, 0, time.UTC)
+	limiter := newHostLimiter(RateLimit{RequestsPerSecond: 2, Burst: 2}, start)
+	tests := []struct {
+		name      string
+		at        time.Duration
+		wantDelay time.Duration
+		wantOK    bool
+	}{
+		{"should allow a burst", 0, 0, true},
+		{"should allow the rest of the burst", 0, 0, true},
+		{"should ask to wait once the burst is spent", 0, 500 * time.Millisecond, false},
+		{"should ask to wait less as time passes", 250 * time.Millisecond, 250 * time.Millisecond, false},
+		{"should allow requests at the rate", 500 * time.Millisecond, 0, true},
+		{"should not let tokens pile up beyond the burst", time.Hour, 0, true},
+		{"should allow the burst again", time.Hour, 0, true},
+		{"should ask to wait after the burst again", time.Hour, 500 * time.Millisecond, false},
+	}
+	for _, tt := range tests {
+		delay, ok := limiter.take(start.Add(tt.at))
+		if delay != tt.wantDelay || ok != tt.wantOK {
+			t.Errorf("%q. hostLimiter.take() = %v, %v, want %v, %v", tt.name, delay, ok, tt.wantDelay, tt.wantOK)
+		}
+	}
+}
+
+func TestClient_RateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	host := server.Listener.Addr().String()
+
+	client := NewClient(WithRateLimit(host, RateLimit{RequestsPerSecond: 20}))
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := client.get(context.Background(), server.URL); err != nil {
+			t.Fatalf("Client.get() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Client.get() sent 3 requests in %v, want at least 100ms at 20 requests per second", elapsed)
+	}
+}
+
+func TestClient_RateLimitConcurrency(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	client := NewClient(WithDefaultRateLimit(RateLimit{MaxConcurrent: 1}))
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	held, err := client.Do(request)
+	if err != nil {
+		t.Fatalf("Client.Do() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.get(ctx, server.URL); err != context.DeadlineExceeded {
+		t.Errorf("Client.get() error = %v while the only slot is held, want %v", err, context.DeadlineExceeded)
+	}
+
+	held.Body.Close()
+	if _, err := client.get(context.Background(), server.URL); err != nil {
+		t.Errorf("Client.get() error = %v after the slot was released", err)
+	}
+}
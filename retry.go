@@ -0,0 +1,167 @@
+package golyrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy decides whether and when failed requests are sent again.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is sent,
+	// including the first one. Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry.
+	// It doubles for every following retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts. A host asking with
+	// Retry-After to wait longer than MaxDelay is not retried.
+	MaxDelay time.Duration
+	// Jitter is the fraction, from 0 to 1, of each delay that is randomized
+	// so that clients failing together do not retry together.
+	Jitter float64
+	// Retryable reports whether a request failing with err is worth retrying.
+	// DefaultRetryable is used when nil.
+	Retryable func(err error) bool
+	// OnRetry, if set, is called before waiting for each retry.
+	OnRetry func(retry Retry)
+}
+
+// Retry describes a retry about to happen.
+type Retry struct {
+	Request *http.Request
+	// Attempt is the number of the attempt that failed, starting at 1.
+	Attempt int
+	// Err is why the attempt failed.
+	Err error
+	// Delay is how long the Client waits before the next attempt.
+	Delay time.Duration
+}
+
+// DefaultRetryPolicy is the RetryPolicy of Clients created without WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+	Jitter:      0.5,
+}
+
+// WithRetryPolicy sets how the Client retries failed requests.
+// Use the zero RetryPolicy to disable retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// DefaultRetryable retries rate limited requests, 5xx responses,
+// timeouts and connections that failed or were cut short. Unknown hosts,
+// cancelled requests and other failures, like invalid certificates
+// or unsupported URLs, are not retried.
+func DefaultRetryable(err error) bool {
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUpstream) {
+		return true
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	// Every *url.Error is a net.Error, whatever failed.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+var (
+	jitterMu   sync.Mutex
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// delay returns how long to wait after attempt failed with err,
+// or false if the request should not be retried.
+func (p RetryPolicy) delay(attempt int, err error) (time.Duration, bool) {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		if p.MaxDelay > 0 && statusErr.RetryAfter > p.MaxDelay {
+			return 0, false
+		}
+		return statusErr.RetryAfter, true
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		jitterMu.Lock()
+		random := jitterRand.Float64()
+		jitterMu.Unlock()
+		delay -= time.Duration(p.Jitter * random * float64(delay))
+	}
+	return delay, true
+}
+
+// retry sends request with send, retrying it as the Client's policy allows.
+func (c *Client) retry(request *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	policy := c.retryPolicy
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = DefaultRetryable
+	}
+	ctx := request.Context()
+	for attempt := 1; ; attempt++ {
+		response, err := send(request)
+		if err == nil || attempt >= policy.MaxAttempts || !retryable(err) {
+			return response, err
+		}
+		if request.Body != nil && request.GetBody == nil {
+			return nil, err
+		}
+		delay, ok := policy.delay(attempt, err)
+		if !ok {
+			return nil, err
+		}
+		if policy.OnRetry != nil {
+			policy.OnRetry(Retry{Request: request, Attempt: attempt, Err: err, Delay: delay})
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+		if request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			request = request.Clone(ctx)
+			request.Body = body
+		}
+	}
+}
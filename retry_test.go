@@ -0,0 +1,192 @@
+package golyrics
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_delay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	tests := []struct {
+		name    string
+		attempt int
+		err     error
+		want    time.Duration
+		wantOK  bool
+	}{
+		{
+			name:    "should wait the base delay after the first attempt",
+			attempt: 1,
+			err:     ErrUpstream,
+			want:    time.Second,
+			wantOK:  true,
+		},
+		{
+			name:    "should double the delay for each attempt",
+			attempt: 3,
+			err:     ErrUpstream,
+			want:    4 * time.Second,
+			wantOK:  true,
+		},
+		{
+			name:    "should cap the delay",
+			attempt: 10,
+			err:     ErrUpstream,
+			want:    5 * time.Second,
+			wantOK:  true,
+		},
+		{
+			name:    "should honour Retry-After",
+			attempt: 1,
+			err:     &StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: 3 * time.Second},
+			want:    3 * time.Second,
+			wantOK:  true,
+		},
+		{
+			name:    "should give up when Retry-After is longer than the maximum delay",
+			attempt: 1,
+			err:     &StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute},
+			wantOK:  false,
+		},
+	}
+	for _, tt := range tests {
+		got, ok := policy.delay(tt.attempt, tt.err)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%q. RetryPolicy.delay() = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got, _ := policy.delay(2, ErrUpstream); got < time.Second || got > 2*time.Second {
+			t.Fatalf("RetryPolicy.delay() with jitter = %v, want between 1s and 2s", got)
+		}
+	}
+}
+
+func TestDefaultRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"should retry rate limited requests", &StatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{"should retry server errors", &StatusError{StatusCode: http.StatusBadGateway}, true},
+		{"should not retry client errors", &StatusError{StatusCode: http.StatusNotFound}, false},
+		{"should not retry cancelled requests", context.Canceled, false},
+		{"should not retry parse errors", &ParseError{Err: errors.New("bad")}, false},
+		{
+			"should retry refused connections",
+			&url.Error{Op: "Get", URL: "http://x/", Err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}},
+			true,
+		},
+		{"should retry reset connections", &url.Error{Op: "Get", URL: "http://x/", Err: syscall.ECONNRESET}, true},
+		{"should retry responses cut short", &url.Error{Op: "Get", URL: "http://x/", Err: io.ErrUnexpectedEOF}, true},
+		{"should retry timeouts", &url.Error{Op: "Get", URL: "http://x/", Err: &net.DNSError{Err: "timeout", IsTimeout: true}}, true},
+		{
+			"should not retry unknown hosts",
+			&url.Error{Op: "Get", URL: "http://x/", Err: &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", IsNotFound: true}}},
+			false,
+		},
+		{"should not retry unsupported schemes", &url.Error{Op: "Get", URL: "htp://x/", Err: errors.New(`unsupported protocol scheme "htp"`)}, false},
+		{"should not retry invalid certificates", &url.Error{Op: "Get", URL: "https://x/", Err: x509.UnknownAuthorityError{}}, false},
+	}
+	for _, tt := range tests {
+		if got := DefaultRetryable(tt.err); got != tt.want {
+			t.Errorf("%q. DefaultRetryable() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClient_DoRetries(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"suggestions":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		maxAttempts  int
+		wantRequests int32
+		wantRetries  int
+		wantErr      error
+	}{
+		{
+			name:         "should retry until the request succeeds",
+			maxAttempts:  3,
+			wantRequests: 3,
+			wantRetries:  2,
+		},
+		{
+			name:         "should give up after the maximum attempts",
+			maxAttempts:  2,
+			wantRequests: 2,
+			wantRetries:  1,
+			wantErr:      ErrRateLimited,
+		},
+	}
+	for _, tt := range tests {
+		atomic.StoreInt32(&requests, 0)
+		var retries []Retry
+		client := NewClient(WithRetryPolicy(RetryPolicy{
+			MaxAttempts: tt.maxAttempts,
+			BaseDelay:   time.Millisecond,
+			OnRetry: func(retry Retry) {
+				retries = append(retries, retry)
+			},
+		}))
+		_, err := client.get(context.Background(), server.URL, "application/json")
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%q. Client.get() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if got := atomic.LoadInt32(&requests); got != tt.wantRequests {
+			t.Errorf("%q. Client.get() sent %d requests, want %d", tt.name, got, tt.wantRequests)
+		}
+		if len(retries) != tt.wantRetries {
+			t.Errorf("%q. OnRetry called %d times, want %d", tt.name, len(retries), tt.wantRetries)
+			continue
+		}
+		if retries[0].Attempt != 1 || !errors.Is(retries[0].Err, ErrUpstream) {
+			t.Errorf("%q. first retry = %+v, want attempt 1 after an upstream error", tt.name, retries[0])
+		}
+	}
+}
+
+func TestClient_DoRetriesCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	client := NewClient(WithRetryPolicy(RetryPolicy{MaxAttempts: 10, BaseDelay: time.Hour}))
+	start := time.Now()
+	if _, err := client.get(ctx, server.URL); err != context.DeadlineExceeded {
+		t.Errorf("Client.get() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Client.get() waited %v for a retry after cancellation", elapsed)
+	}
+}
@@ -0,0 +1,100 @@
+package golyrics
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultRevalidationWindow is how long cached lyrics are kept after they
+// expire, so that they can be revalidated instead of downloaded again.
+const DefaultRevalidationWindow = 7 * 24 * time.Hour
+
+// WithRevalidationWindow sets how long cached lyrics are kept after they
+// expire. Expired lyrics fetched from pages with an ETag or a Last-Modified
+// date are refreshed with a conditional request, and a 304 Not Modified
+// response makes the Client reuse them. Zero disables revalidation.
+func WithRevalidationWindow(window time.Duration) Option {
+	return func(c *Client) {
+		c.revalidationWindow = window
+	}
+}
+
+// validator holds the validators of a response.
+type validator struct {
+	ETag         string `json:",omitempty"`
+	LastModified string `json:",omitempty"`
+}
+
+// revalidation carries validators through the context of a fetch:
+// the ones to send with conditional requests
+// and the ones collected from the responses.
+type revalidation struct {
+	known map[string]validator
+
+	mu   sync.Mutex
+	seen map[string]validator
+}
+
+type revalidationKey struct{}
+
+// withRevalidation returns a context making the Client send conditional
+// requests for the URLs in known and collect the validators of responses.
+func withRevalidation(ctx context.Context, known map[string]validator) (context.Context, *revalidation) {
+	r := &revalidation{known: known, seen: map[string]validator{}}
+	return context.WithValue(ctx, revalidationKey{}, r), r
+}
+
+func revalidationFrom(ctx context.Context) *revalidation {
+	r, _ := ctx.Value(revalidationKey{}).(*revalidation)
+	return r
+}
+
+// prepare adds the conditional headers for request, if its URL is known.
+func (r *revalidation) prepare(request *http.Request) {
+	if request.Method != http.MethodGet {
+		return
+	}
+	v, ok := r.known[request.URL.String()]
+	if !ok {
+		return
+	}
+	if v.ETag != "" {
+		request.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		request.Header.Set("If-Modified-Since", v.LastModified)
+	}
+}
+
+// record collects the validators of response, if it has any.
+func (r *revalidation) record(response *http.Response) {
+	v := validator{
+		ETag:         response.Header.Get("ETag"),
+		LastModified: response.Header.Get("Last-Modified"),
+	}
+	if v == (validator{}) {
+		return
+	}
+	r.mu.Lock()
+	r.seen[response.Request.URL.String()] = v
+	r.mu.Unlock()
+}
+
+// forget drops the validators collected for URL, so that it is
+// downloaded again instead of revalidated on the next fetch.
+func (r *revalidation) forget(URL string) {
+	r.mu.Lock()
+	delete(r.seen, URL)
+	r.mu.Unlock()
+}
+
+func (r *revalidation) validators() map[string]validator {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.seen) == 0 {
+		return nil
+	}
+	return r.seen
+}
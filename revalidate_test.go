@@ -0,0 +1,136 @@
+package golyrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_FetchRevalidates(t *testing.T) {
+	var version, fullResponses, notModified int32
+	version = 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := fmt.Sprintf(`"v%d"`, atomic.LoadInt32(&version))
+		if r.Header.Get("If-None-Match") == etag {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&fullResponses, 1)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<div class='lyricbox'>Version %d</div>`, atomic.LoadInt32(&version))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithLyricsBaseURI(server.URL+"/wiki/"),
+		WithCache(NewMemoryCache(0, 0)),
+		WithCacheTTL(time.Millisecond, time.Hour),
+	)
+	tests := []struct {
+		name            string
+		version         int32
+		wantLyrics      string
+		wantRevalidated bool
+		wantFull        int32
+		wantNotModified int32
+	}{
+		{
+			name:       "should download lyrics that are not cached",
+			version:    1,
+			wantLyrics: "Version 1",
+			wantFull:   1,
+		},
+		{
+			name:            "should reuse expired lyrics that did not change",
+			version:         1,
+			wantLyrics:      "Version 1",
+			wantRevalidated: true,
+			wantFull:        1,
+			wantNotModified: 1,
+		},
+		{
+			name:            "should download expired lyrics that changed",
+			version:         2,
+			wantLyrics:      "Version 2",
+			wantFull:        2,
+			wantNotModified: 1,
+		},
+	}
+	for _, tt := range tests {
+		atomic.StoreInt32(&version, tt.version)
+		time.Sleep(5 * time.Millisecond)
+		track := Track{Artist: "Blackfield", Name: "Pain"}
+		result, err := client.FetchResult(context.Background(), &track)
+		if err != nil {
+			t.Errorf("%q. Client.FetchResult() error = %v", tt.name, err)
+			continue
+		}
+		if track.Lyrics != tt.wantLyrics || result.Revalidated != tt.wantRevalidated {
+			t.Errorf("%q. Client.FetchResult() = %+v with lyrics %q, want Revalidated %v with lyrics %q",
+				tt.name, result, track.Lyrics, tt.wantRevalidated, tt.wantLyrics)
+		}
+		if fullResponses != tt.wantFull || notModified != tt.wantNotModified {
+			t.Errorf("%q. server sent %d full and %d not modified responses, want %d and %d",
+				tt.name, fullResponses, notModified, tt.wantFull, tt.wantNotModified)
+		}
+	}
+}
+
+func TestRevalidation_prepare(t *testing.T) {
+	tests := []struct {
+		name              string
+		method            string
+		known             map[string]validator
+		wantNoneMatch     string
+		wantModifiedSince string
+	}{
+		{
+			name:              "should send the validators of known URLs",
+			method:            http.MethodGet,
+			known:             map[string]validator{"http://lyrics/wiki/A:B": {ETag: `"x"`, LastModified: "Sat, 30 Jun 2018 12:00:00 GMT"}},
+			wantNoneMatch:     `"x"`,
+			wantModifiedSince: "Sat, 30 Jun 2018 12:00:00 GMT",
+		},
+		{
+			name:   "should not make other requests conditional",
+			method: http.MethodGet,
+			known:  map[string]validator{"http://lyrics/wiki/C:D": {ETag: `"x"`}},
+		},
+		{
+			name:   "should not make requests other than GET conditional",
+			method: http.MethodPost,
+			known:  map[string]validator{"http://lyrics/wiki/A:B": {ETag: `"x"`}},
+		},
+	}
+	for _, tt := range tests {
+		_, r := withRevalidation(context.Background(), tt.known)
+		request, _ := http.NewRequest(tt.method, "http://lyrics/wiki/A:B", nil)
+		r.prepare(request)
+		if got := request.Header.Get("If-None-Match"); got != tt.wantNoneMatch {
+			t.Errorf("%q. If-None-Match = %q, want %q", tt.name, got, tt.wantNoneMatch)
+		}
+		if got := request.Header.Get("If-Modified-Since"); got != tt.wantModifiedSince {
+			t.Errorf("%q. If-Modified-Since = %q, want %q", tt.name, got, tt.wantModifiedSince)
+		}
+	}
+}
+
+func TestRevalidation_forget(t *testing.T) {
+	_, r := withRevalidation(context.Background(), nil)
+	for _, URL := range []string{"http://lyrics/wiki/Old:Name", "http://lyrics/wiki/Blackfield:Pain"} {
+		request := httptest.NewRequest(http.MethodGet, URL, nil)
+		r.record(&http.Response{Request: request, Header: http.Header{"Etag": {`"v1"`}}})
+	}
+	r.forget("http://lyrics/wiki/Old:Name")
+	want := map[string]validator{"http://lyrics/wiki/Blackfield:Pain": {ETag: `"v1"`}}
+	if got := r.validators(); !reflect.DeepEqual(got, want) {
+		t.Errorf("revalidation.validators() = %v, want %v", got, want)
+	}
+}
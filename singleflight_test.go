@@ -0,0 +1,156 @@
+package golyrics
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForWaiters blocks until n callers wait for the call under key.
+func waitForWaiters(group *flightGroup, key string, n int) {
+	for {
+		group.mu.Lock()
+		f := group.flights[key]
+		waiting := f != nil && f.waiters == n
+		group.mu.Unlock()
+		if waiting {
+			return
+		}
+		runtime.Gosched()
+	}
+}
+
+func TestFlightGroup_do(t *testing.T) {
+	group := &flightGroup{}
+	release := make(chan struct{})
+	var calls int32
+	fn := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "lyrics", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := group.do(context.Background(), "key", fn)
+			if value != "lyrics" || err != nil {
+				t.Errorf("flightGroup.do() = %v, %v, want %v, nil", value, err, "lyrics")
+			}
+		}()
+	}
+	waitForWaiters(group, "key", 10)
+	close(release)
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("flightGroup.do() called fn %d times, want 1", calls)
+	}
+
+	group.do(context.Background(), "key", fn)
+	if calls != 2 {
+		t.Errorf("flightGroup.do() reused a finished call")
+	}
+}
+
+func TestFlightGroup_doCancelled(t *testing.T) {
+	group := &flightGroup{}
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (interface{}, error) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			close(cancelled)
+			return nil, ctx.Err()
+		case <-release:
+			return "lyrics", nil
+		}
+	}
+
+	leaving, leave := context.WithCancel(context.Background())
+	staying, stay := context.WithCancel(context.Background())
+	results := make(chan error, 2)
+	go func() {
+		_, err := group.do(leaving, "key", fn)
+		results <- err
+	}()
+	<-started
+	go func() {
+		value, err := group.do(staying, "key", fn)
+		if err == nil && value != "lyrics" {
+			t.Errorf("flightGroup.do() = %v, want %v", value, "lyrics")
+		}
+		results <- err
+	}()
+	waitForWaiters(group, "key", 2)
+
+	leave()
+	if err := <-results; err != context.Canceled {
+		t.Errorf("flightGroup.do() error = %v for the cancelled waiter, want %v", err, context.Canceled)
+	}
+	select {
+	case <-cancelled:
+		t.Fatalf("flightGroup.do() cancelled the call while a waiter remained")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	stay()
+	if err := <-results; err != context.Canceled {
+		t.Errorf("flightGroup.do() error = %v for the last waiter, want %v", err, context.Canceled)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Errorf("flightGroup.do() did not cancel the call once every waiter left")
+	}
+	close(release)
+}
+
+// blockingProvider counts fetches and answers them once released.
+type blockingProvider struct {
+	staticProvider
+	fetches int32
+	release chan struct{}
+}
+
+func (p *blockingProvider) Fetch(ctx context.Context, track *Track) error {
+	atomic.AddInt32(&p.fetches, 1)
+	<-p.release
+	return p.staticProvider.Fetch(ctx, track)
+}
+
+func TestClient_FetchDeduplicated(t *testing.T) {
+	provider := &blockingProvider{staticProvider: staticProvider{name: "blocking", lyrics: "Pain"}, release: make(chan struct{})}
+	client := NewClient(WithProviderInstance(provider))
+
+	var wg sync.WaitGroup
+	tracks := make([]Track, 5)
+	for i := range tracks {
+		tracks[i] = Track{Artist: "Blackfield", Name: "Pain"}
+		wg.Add(1)
+		go func(track *Track) {
+			defer wg.Done()
+			if err := client.Fetch(track); err != nil {
+				t.Errorf("Client.Fetch() error = %v", err)
+			}
+		}(&tracks[i])
+	}
+	waitForWaiters(client.flights, client.lyricsCacheKey(&Track{Artist: "Blackfield", Name: "Pain"}), len(tracks))
+	close(provider.release)
+	wg.Wait()
+
+	if provider.fetches != 1 {
+		t.Errorf("Client.Fetch() reached the provider %d times, want 1", provider.fetches)
+	}
+	for _, track := range tracks {
+		if track.Lyrics != "Pain" {
+			t.Errorf("Client.Fetch() lyrics = %q, want %q", track.Lyrics, "Pain")
+		}
+	}
+}
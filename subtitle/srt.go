@@ -0,0 +1,52 @@
+package subtitle
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/mamal72/golyrics"
+)
+
+// srtTagPattern matches the formatting tags of SRT subtitles.
+var srtTagPattern = regexp.MustCompile(`(?i)</?(?:b|i|u|font)\b[^>]*>`)
+
+// WriteSRT writes lyrics to w as SRT subtitles.
+func WriteSRT(w io.Writer, lyrics golyrics.SyncedLyrics) error {
+	var text strings.Builder
+	for i, line := range cues(lyrics, false) {
+		fmt.Fprintf(&text, "%d\n%s --> %s\n%s\n\n", i+1,
+			formatTimestamp(line.Start, ","), formatTimestamp(line.End, ","), line.Text)
+	}
+	_, err := io.WriteString(w, text.String())
+	return err
+}
+
+// ReadSRT reads lyrics from SRT subtitles. Formatting tags are removed,
+// the lines of a cue are joined, and malformed cues are skipped.
+// It fails with ErrFormat when no cue can be read from r.
+func ReadSRT(r io.Reader) (golyrics.SyncedLyrics, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return golyrics.SyncedLyrics{}, err
+	}
+	var lyrics golyrics.SyncedLyrics
+	for _, block := range blocks(string(data)) {
+		// The timing line follows the number of the cue, which may be missing.
+		for i := 0; i < len(block) && i < 2; i++ {
+			start, end, ok := parseTiming(block[i])
+			if !ok {
+				continue
+			}
+			text := srtTagPattern.ReplaceAllString(strings.Join(block[i+1:], " "), "")
+			lyrics.Lines = append(lyrics.Lines, golyrics.SyncedLine{Start: start, End: end, Text: strings.TrimSpace(text)})
+			break
+		}
+	}
+	if len(lyrics.Lines) == 0 && strings.TrimSpace(string(data)) != "" {
+		return golyrics.SyncedLyrics{}, fmt.Errorf("%w: no SRT cues", ErrFormat)
+	}
+	return sorted(lyrics), nil
+}
@@ -0,0 +1,70 @@
+package subtitle
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mamal72/golyrics"
+)
+
+func TestWriteSRT(t *testing.T) {
+	var document strings.Builder
+	if err := WriteSRT(&document, pain); err != nil {
+		t.Fatalf("WriteSRT() error = %v", err)
+	}
+	want := "1\n00:00:12,000 --> 00:00:15,000\nPain\n\n" +
+		"2\n00:00:15,000 --> 00:00:17,000\nCan't run & <hide>\n\n" +
+		"3\n00:00:20,000 --> 00:00:21,000\nGo away\n\n" +
+		"4\n01:02:03,004 --> 01:02:03,504\nLate\n\n"
+	if document.String() != want {
+		t.Errorf("WriteSRT() wrote %q, want %q", document.String(), want)
+	}
+}
+
+func TestReadSRT(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     golyrics.SyncedLyrics
+		wantErr  error
+	}{
+		{
+			name: "should read cues, joining their lines and removing formatting",
+			document: "\ufeff1\r\n00:00:15,000 --> 00:00:17,500\r\n<i>Can't</i> <font color=\"red\">run</font>\r\n<b>away</b>\r\n\r\n" +
+				"2\r\n00:00:12.000 --> 00:00:15.000 X1:0 X2:10\r\nPain\r\n",
+			want: golyrics.SyncedLyrics{Lines: []golyrics.SyncedLine{
+				{Start: 12 * time.Second, End: 15 * time.Second, Text: "Pain"},
+				{Start: 15 * time.Second, End: 17500 * time.Millisecond, Text: "Can't run away"},
+			}},
+		},
+		{
+			name:     "should skip malformed cues",
+			document: "1\n00:00:12 --> soon\nPain\n\n00:00:15,000 --> 00:00:17,000\nNo number\n\njust text",
+			want: golyrics.SyncedLyrics{Lines: []golyrics.SyncedLine{
+				{Start: 15 * time.Second, End: 17 * time.Second, Text: "No number"},
+			}},
+		},
+		{
+			name:     "should read nothing from empty documents",
+			document: "\n\n",
+		},
+		{
+			name:     "should fail for documents without cues",
+			document: "WEBVTT\n\nNOTE nothing",
+			wantErr:  ErrFormat,
+		},
+	}
+	for _, tt := range tests {
+		got, err := ReadSRT(strings.NewReader(tt.document))
+		if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+			t.Errorf("%q. ReadSRT() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. ReadSRT() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
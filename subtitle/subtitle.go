@@ -0,0 +1,153 @@
+// Package subtitle writes synced lyrics as SRT, WebVTT and TTML subtitles,
+// so that they can be shown over music videos, and reads them back.
+//
+// Every line of lyrics with text becomes a cue. Lines and words without
+// an end are interpolated first, as by golyrics.SyncedLyrics.Interpolate:
+// interpolate the lyrics yourself with the length of the song
+// to give the last cue the right end.
+package subtitle
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mamal72/golyrics"
+)
+
+// ErrFormat is returned when a document is not in the expected format.
+var ErrFormat = errors.New("subtitle: malformed document")
+
+// Options changes how subtitles are written.
+type Options struct {
+	// Words writes the times of words in WebVTT and TTML documents.
+	// Lines timed as a whole have their time split evenly between their words.
+	Words bool
+	// CueSettings are the settings of every WebVTT cue,
+	// like "line:85% align:center".
+	CueSettings string
+}
+
+// timestampPattern matches a timestamp like 01:02:03.456, 01:02:03,456 or 02:03.456.
+var timestampPattern = regexp.MustCompile(`^(?:(\d+):)?(\d{1,2}):(\d{1,2})[.,](\d{1,3})$`)
+
+// cues returns the lines of lyrics written as cues: the lines with text,
+// with their ends interpolated, and with their words when words is set.
+func cues(lyrics golyrics.SyncedLyrics, words bool) []golyrics.SyncedLine {
+	var lines []golyrics.SyncedLine
+	for _, line := range lyrics.Interpolate(0).Lines {
+		line.Text = strings.TrimSpace(newlines.Replace(line.Text))
+		if line.Text == "" {
+			continue
+		}
+		if !words {
+			line.Words = nil
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// newlines replaces the line breaks of text being written.
+var newlines = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
+// formatTimestamp formats t as hh:mm:ss.ttt, with sep before the milliseconds.
+func formatTimestamp(t time.Duration, sep string) string {
+	if t < 0 {
+		t = 0
+	}
+	t = t.Round(time.Millisecond)
+	hours := t / time.Hour
+	minutes := (t % time.Hour) / time.Minute
+	seconds := (t % time.Minute) / time.Second
+	ms := (t % time.Second) / time.Millisecond
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", hours, minutes, seconds, sep, ms)
+}
+
+// parseTimestamp parses a timestamp of SRT or WebVTT cues.
+func parseTimestamp(text string) (time.Duration, bool) {
+	match := timestampPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return 0, false
+	}
+	hours, _ := strconv.Atoi(match[1])
+	minutes, _ := strconv.Atoi(match[2])
+	seconds, _ := strconv.Atoi(match[3])
+	if minutes >= 60 || seconds >= 60 {
+		return 0, false
+	}
+	ms, _ := strconv.Atoi(match[4])
+	for i := len(match[4]); i < 3; i++ {
+		ms *= 10
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+		time.Duration(seconds)*time.Second + time.Duration(ms)*time.Millisecond, true
+}
+
+// parseTiming parses the timing line of a cue, like
+// 00:00:12.000 --> 00:00:15.000 line:85%, ignoring the settings after it.
+func parseTiming(line string) (start, end time.Duration, ok bool) {
+	i := strings.Index(line, "-->")
+	if i < 0 {
+		return 0, 0, false
+	}
+	fields := strings.Fields(line[i+3:])
+	if len(fields) == 0 {
+		return 0, 0, false
+	}
+	start, ok = parseTimestamp(line[:i])
+	if !ok {
+		return 0, 0, false
+	}
+	end, ok = parseTimestamp(fields[0])
+	return start, end, ok
+}
+
+// blocks splits text into its blocks of lines separated by blank lines.
+func blocks(text string) [][]string {
+	text = strings.TrimPrefix(text, "\ufeff")
+	text = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(text)
+	var found [][]string
+	var block []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			if block != nil {
+				found = append(found, block)
+				block = nil
+			}
+			continue
+		}
+		block = append(block, line)
+	}
+	if block != nil {
+		found = append(found, block)
+	}
+	return found
+}
+
+// sorted sorts the lines of lyrics by start.
+func sorted(lyrics golyrics.SyncedLyrics) golyrics.SyncedLyrics {
+	sort.SliceStable(lyrics.Lines, func(i, j int) bool {
+		return lyrics.Lines[i].Start < lyrics.Lines[j].Start
+	})
+	return lyrics
+}
+
+// endWords ends the words of line without an end when the next one
+// starts, and the last one, or those starting with the next, with the line.
+func endWords(line *golyrics.SyncedLine) {
+	for i := range line.Words {
+		word := &line.Words[i]
+		if word.End > word.Start {
+			continue
+		}
+		word.End = line.End
+		if i+1 < len(line.Words) && line.Words[i+1].Start > word.Start {
+			word.End = line.Words[i+1].Start
+		}
+	}
+}
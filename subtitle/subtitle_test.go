@@ -0,0 +1,135 @@
+package subtitle
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mamal72/golyrics"
+)
+
+// pain holds lyrics with every kind of line the writers handle.
+var pain = golyrics.SyncedLyrics{Lines: []golyrics.SyncedLine{
+	{Start: 12 * time.Second, End: 15 * time.Second, Text: "Pain"},
+	{
+		Start: 15 * time.Second,
+		Text:  "Can't run & <hide>",
+		Words: []golyrics.SyncedWord{
+			{Start: 15 * time.Second, Text: "Can't"},
+			{Start: 15500 * time.Millisecond, End: 16 * time.Second, Text: "run"},
+			{Start: 16200 * time.Millisecond, Text: "&"},
+			{Start: 16400 * time.Millisecond, Text: "<hide>"},
+		},
+	},
+	{Start: 17 * time.Second},
+	{Start: 20 * time.Second, Text: "Go away"},
+	{Start: 21 * time.Second},
+	{Start: time.Hour + 2*time.Minute + 3004*time.Millisecond, Text: "Late"},
+}}
+
+func TestRoundTrip(t *testing.T) {
+	interpolated := pain.Interpolate(0)
+	// lines returns the interpolated lines with text, with or without their words.
+	// Without gaps, words end when the next one starts, as in WebVTT.
+	lines := func(words, gaps bool) golyrics.SyncedLyrics {
+		var want golyrics.SyncedLyrics
+		for _, line := range interpolated.Lines {
+			if line.Text == "" {
+				continue
+			}
+			line.Words = append([]golyrics.SyncedWord(nil), line.Words...)
+			if !words {
+				line.Words = nil
+			}
+			if !gaps {
+				for i := range line.Words {
+					line.Words[i].End = 0
+				}
+				endWords(&line)
+			}
+			want.Lines = append(want.Lines, line)
+		}
+		return want
+	}
+
+	tests := []struct {
+		name  string
+		write func(io.Writer, golyrics.SyncedLyrics) error
+		read  func(io.Reader) (golyrics.SyncedLyrics, error)
+		want  golyrics.SyncedLyrics
+	}{
+		{
+			name:  "SRT",
+			write: WriteSRT,
+			read:  ReadSRT,
+			want:  lines(false, false),
+		},
+		{
+			name: "WebVTT",
+			write: func(w io.Writer, lyrics golyrics.SyncedLyrics) error {
+				return WriteWebVTT(w, lyrics, Options{CueSettings: "line:85%"})
+			},
+			read: ReadWebVTT,
+			want: lines(false, false),
+		},
+		{
+			name: "WebVTT with words",
+			write: func(w io.Writer, lyrics golyrics.SyncedLyrics) error {
+				return WriteWebVTT(w, lyrics, Options{Words: true})
+			},
+			read: ReadWebVTT,
+			want: lines(true, false),
+		},
+		{
+			name: "TTML",
+			write: func(w io.Writer, lyrics golyrics.SyncedLyrics) error {
+				return WriteTTML(w, lyrics, Options{})
+			},
+			read: ReadTTML,
+			want: lines(false, false),
+		},
+		{
+			name: "TTML with words",
+			write: func(w io.Writer, lyrics golyrics.SyncedLyrics) error {
+				return WriteTTML(w, lyrics, Options{Words: true})
+			},
+			read: ReadTTML,
+			want: lines(true, true),
+		},
+	}
+	for _, tt := range tests {
+		var document bytes.Buffer
+		if err := tt.write(&document, pain); err != nil {
+			t.Errorf("%q. write error = %v", tt.name, err)
+			continue
+		}
+		got, err := tt.read(&document)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. read(write()) = %+v, %v, want %+v", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func Test_parseTimestamp(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   time.Duration
+		wantOk bool
+	}{
+		{text: "01:02:03,456", want: time.Hour + 2*time.Minute + 3456*time.Millisecond, wantOk: true},
+		{text: "00:00:12.5", want: 12500 * time.Millisecond, wantOk: true},
+		{text: " 02:03.004 ", want: 2*time.Minute + 3004*time.Millisecond, wantOk: true},
+		{text: "100:00:00.000", want: 100 * time.Hour, wantOk: true},
+		{text: "00:60:00.000"},
+		{text: "00:00:12"},
+		{text: "c"},
+	}
+	for _, tt := range tests {
+		got, ok := parseTimestamp(tt.text)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("%q. parseTimestamp() = %v, %v, want %v, %v", tt.text, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
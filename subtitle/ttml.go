@@ -0,0 +1,176 @@
+package subtitle
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mamal72/golyrics"
+)
+
+var (
+	// clockTimePattern matches a TTML clock time like 00:01:02.345.
+	clockTimePattern = regexp.MustCompile(`^(\d+):(\d{2}):(\d{2})(?:\.(\d+))?$`)
+	// offsetTimePattern matches a TTML offset time like 62.345s or 1500ms.
+	offsetTimePattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)(h|m|s|ms)$`)
+)
+
+// WriteTTML writes lyrics to w as a TTML document, with a <p> per line.
+// With options.Words, every word is put in a <span>. As in TTML,
+// the times of spans are relative to the start of their paragraph.
+func WriteTTML(w io.Writer, lyrics golyrics.SyncedLyrics, options Options) error {
+	var text strings.Builder
+	text.WriteString(xml.Header)
+	text.WriteString(`<tt xmlns="http://www.w3.org/ns/ttml" xml:lang="">` + "\n")
+	text.WriteString("  <body>\n    <div>\n")
+	for _, line := range cues(lyrics, options.Words) {
+		fmt.Fprintf(&text, `      <p begin="%s" end="%s">`, formatTimestamp(line.Start, "."), formatTimestamp(line.End, "."))
+		if len(line.Words) == 0 {
+			xml.EscapeText(&text, []byte(line.Text))
+		}
+		for i, word := range line.Words {
+			if i > 0 {
+				text.WriteString(" ")
+			}
+			fmt.Fprintf(&text, `<span begin="%s" end="%s">`,
+				formatTimestamp(word.Start-line.Start, "."), formatTimestamp(word.End-line.Start, "."))
+			xml.EscapeText(&text, []byte(newlines.Replace(word.Text)))
+			text.WriteString("</span>")
+		}
+		text.WriteString("</p>\n")
+	}
+	text.WriteString("    </div>\n  </body>\n</tt>\n")
+	_, err := io.WriteString(w, text.String())
+	return err
+}
+
+// ReadTTML reads lyrics from a TTML document, with a line per <p>
+// and a word per <span> with its own times. Whitespace is collapsed,
+// and <br> elements are read as spaces.
+// It fails with ErrFormat when r is not a TTML document.
+func ReadTTML(r io.Reader) (golyrics.SyncedLyrics, error) {
+	var lyrics golyrics.SyncedLyrics
+	decoder := xml.NewDecoder(r)
+	// bases holds the start of the open elements, that the times
+	// of their children are relative to.
+	var bases []time.Duration
+	var line *golyrics.SyncedLine
+	var text, word strings.Builder
+	var wordStart, wordEnd time.Duration
+	lineDepth, wordDepth, root := 0, 0, false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return golyrics.SyncedLyrics{}, fmt.Errorf("%w: %v", ErrFormat, err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if !root && token.Name.Local != "tt" {
+				return golyrics.SyncedLyrics{}, fmt.Errorf("%w: no <tt> root element", ErrFormat)
+			}
+			root = true
+			base := time.Duration(0)
+			if len(bases) > 0 {
+				base = bases[len(bases)-1]
+			}
+			start, end, timed := ttmlTimes(token.Attr, base)
+			bases = append(bases, start)
+			switch {
+			case token.Name.Local == "p" && line == nil:
+				line, lineDepth = &golyrics.SyncedLine{Start: start, End: end}, len(bases)
+				text.Reset()
+			case token.Name.Local == "span" && line != nil && timed && wordDepth == 0:
+				wordStart, wordEnd, wordDepth = start, end, len(bases)
+				word.Reset()
+			case token.Name.Local == "br" && line != nil:
+				text.WriteString(" ")
+				word.WriteString(" ")
+			}
+		case xml.CharData:
+			if line != nil {
+				text.Write(token)
+				word.Write(token)
+			}
+		case xml.EndElement:
+			depth := len(bases)
+			bases = bases[:depth-1]
+			switch depth {
+			case wordDepth:
+				if w := collapse(word.String()); w != "" {
+					line.Words = append(line.Words, golyrics.SyncedWord{Start: wordStart, End: wordEnd, Text: w})
+				}
+				wordDepth = 0
+			case lineDepth:
+				line.Text = collapse(text.String())
+				endWords(line)
+				lyrics.Lines = append(lyrics.Lines, *line)
+				line, lineDepth = nil, 0
+			}
+		}
+	}
+	if !root {
+		return golyrics.SyncedLyrics{}, fmt.Errorf("%w: no <tt> root element", ErrFormat)
+	}
+	return sorted(lyrics), nil
+}
+
+// ttmlTimes returns the times of an element from its begin, end and dur
+// attributes, relative to base. timed is false when it has no begin.
+func ttmlTimes(attrs []xml.Attr, base time.Duration) (start, end time.Duration, timed bool) {
+	start = base
+	for _, attr := range attrs {
+		if attr.Name.Local != "begin" {
+			continue
+		}
+		if t, ok := parseTTMLTime(attr.Value); ok {
+			start, timed = base+t, true
+		}
+	}
+	for _, attr := range attrs {
+		t, ok := parseTTMLTime(attr.Value)
+		switch {
+		case !ok:
+		case attr.Name.Local == "end":
+			end = base + t
+		case attr.Name.Local == "dur" && end == 0:
+			end = start + t
+		}
+	}
+	return start, end, timed
+}
+
+// parseTTMLTime parses a TTML clock time, like 00:01:02.345,
+// or offset time, like 62.345s.
+func parseTTMLTime(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if match := clockTimePattern.FindStringSubmatch(value); match != nil {
+		hours, _ := strconv.Atoi(match[1])
+		minutes, _ := strconv.Atoi(match[2])
+		seconds, _ := strconv.Atoi(match[3])
+		if minutes >= 60 || seconds >= 60 {
+			return 0, false
+		}
+		fraction := (match[4] + "000")[:3]
+		ms, _ := strconv.Atoi(fraction)
+		return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+			time.Duration(seconds)*time.Second + time.Duration(ms)*time.Millisecond, true
+	}
+	if match := offsetTimePattern.FindStringSubmatch(value); match != nil {
+		n, _ := strconv.ParseFloat(match[1], 64)
+		unit := map[string]time.Duration{"h": time.Hour, "m": time.Minute, "s": time.Second, "ms": time.Millisecond}[match[2]]
+		return time.Duration(n * float64(unit)).Round(time.Millisecond), true
+	}
+	return 0, false
+}
+
+// collapse collapses the whitespace of text, as XML does.
+func collapse(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,139 @@
+package subtitle
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mamal72/golyrics"
+)
+
+func TestWriteTTML(t *testing.T) {
+	lyrics := golyrics.SyncedLyrics{Lines: []golyrics.SyncedLine{
+		{Start: 12 * time.Second, End: 15 * time.Second, Text: "Pain & <run>"},
+		{Start: 15 * time.Second, End: 16 * time.Second, Text: "Go away"},
+	}}
+	tests := []struct {
+		name    string
+		options Options
+		want    string
+	}{
+		{
+			name: "should write a paragraph per line",
+			want: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<tt xmlns="http://www.w3.org/ns/ttml" xml:lang="">` + "\n  <body>\n    <div>\n" +
+				`      <p begin="00:00:12.000" end="00:00:15.000">Pain &amp; &lt;run&gt;</p>` + "\n" +
+				`      <p begin="00:00:15.000" end="00:00:16.000">Go away</p>` + "\n" +
+				"    </div>\n  </body>\n</tt>\n",
+		},
+		{
+			name:    "should write words in spans timed from their paragraph",
+			options: Options{Words: true},
+			want: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<tt xmlns="http://www.w3.org/ns/ttml" xml:lang="">` + "\n  <body>\n    <div>\n" +
+				`      <p begin="00:00:12.000" end="00:00:15.000"><span begin="00:00:00.000" end="00:00:01.000">Pain</span> ` +
+				`<span begin="00:00:01.000" end="00:00:02.000">&amp;</span> <span begin="00:00:02.000" end="00:00:03.000">&lt;run&gt;</span></p>` + "\n" +
+				`      <p begin="00:00:15.000" end="00:00:16.000"><span begin="00:00:00.000" end="00:00:00.500">Go</span> ` +
+				`<span begin="00:00:00.500" end="00:00:01.000">away</span></p>` + "\n" +
+				"    </div>\n  </body>\n</tt>\n",
+		},
+	}
+	for _, tt := range tests {
+		var document strings.Builder
+		if err := WriteTTML(&document, lyrics, tt.options); err != nil {
+			t.Errorf("%q. WriteTTML() error = %v", tt.name, err)
+			continue
+		}
+		if document.String() != tt.want {
+			t.Errorf("%q. WriteTTML() wrote %q, want %q", tt.name, document.String(), tt.want)
+		}
+	}
+}
+
+func TestReadTTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     golyrics.SyncedLyrics
+		wantErr  error
+	}{
+		{
+			name: "should read paragraphs with times relative to their parents",
+			document: `<tt xmlns="http://www.w3.org/ns/ttml" xmlns:tts="http://www.w3.org/ns/ttml#styling"><head/><body>` +
+				`<div begin="10s"><p begin="2s" dur="3s">Pain</p>` +
+				`<p begin="00:00:05.5" end="00:00:07.25" tts:color="red"> Can't<br/>run <span tts:fontStyle="italic">away</span> </p></div>` +
+				`<div><p begin="1m" end="61500ms">Go</p><p>Untimed</p></div></body></tt>`,
+			want: golyrics.SyncedLyrics{Lines: []golyrics.SyncedLine{
+				{Text: "Untimed"},
+				{Start: 12 * time.Second, End: 15 * time.Second, Text: "Pain"},
+				{Start: 15500 * time.Millisecond, End: 17250 * time.Millisecond, Text: "Can't run away"},
+				{Start: time.Minute, End: 61500 * time.Millisecond, Text: "Go"},
+			}},
+		},
+		{
+			name: "should read the times of words",
+			document: `<tt><body><div><p begin="00:00:15.000" end="00:00:17.000"><span begin="0s" end="0.4s">Can't</span> ` +
+				`<span begin="0.5s"><span begin="0.1s">r</span>un</span> <span>away</span></p></div></body></tt>`,
+			want: golyrics.SyncedLyrics{Lines: []golyrics.SyncedLine{{
+				Start: 15 * time.Second,
+				End:   17 * time.Second,
+				Text:  "Can't run away",
+				Words: []golyrics.SyncedWord{
+					{Start: 15 * time.Second, End: 15400 * time.Millisecond, Text: "Can't"},
+					{Start: 15500 * time.Millisecond, End: 17 * time.Second, Text: "run"},
+				},
+			}}},
+		},
+		{
+			name:     "should fail for other XML documents",
+			document: `<html><body><p begin="1s">Pain</p></body></html>`,
+			wantErr:  ErrFormat,
+		},
+		{
+			name:     "should fail for malformed XML",
+			document: `<tt><body><p>Pain</body></tt>`,
+			wantErr:  ErrFormat,
+		},
+		{
+			name:     "should fail for empty documents",
+			document: "",
+			wantErr:  ErrFormat,
+		},
+	}
+	for _, tt := range tests {
+		got, err := ReadTTML(strings.NewReader(tt.document))
+		if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+			t.Errorf("%q. ReadTTML() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. ReadTTML() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func Test_parseTTMLTime(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{value: "01:02:03.4567", want: time.Hour + 2*time.Minute + 3456*time.Millisecond, wantOk: true},
+		{value: "00:00:12", want: 12 * time.Second, wantOk: true},
+		{value: "1.5h", want: 90 * time.Minute, wantOk: true},
+		{value: "2m", want: 2 * time.Minute, wantOk: true},
+		{value: " 12.345s ", want: 12345 * time.Millisecond, wantOk: true},
+		{value: "1500ms", want: 1500 * time.Millisecond, wantOk: true},
+		{value: "00:00:12:05"},
+		{value: "12f"},
+		{value: "soon"},
+	}
+	for _, tt := range tests {
+		got, ok := parseTTMLTime(tt.value)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("%q. parseTTMLTime() = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package subtitle
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mamal72/golyrics"
+)
+
+// vttTagPattern matches the tags of WebVTT cue text, including timestamps.
+var vttTagPattern = regexp.MustCompile(`<([^<>]*)>`)
+
+// vttEscaper escapes the text of WebVTT cues.
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// WriteWebVTT writes lyrics to w as WebVTT subtitles. With options.Words,
+// every word is put in a <c> tag following a timestamp tag with its start.
+func WriteWebVTT(w io.Writer, lyrics golyrics.SyncedLyrics, options Options) error {
+	var text strings.Builder
+	text.WriteString("WEBVTT\n\n")
+	for _, line := range cues(lyrics, options.Words) {
+		fmt.Fprintf(&text, "%s --> %s", formatTimestamp(line.Start, "."), formatTimestamp(line.End, "."))
+		if settings := strings.TrimSpace(newlines.Replace(options.CueSettings)); settings != "" {
+			text.WriteString(" " + settings)
+		}
+		text.WriteString("\n")
+		if len(line.Words) == 0 {
+			text.WriteString(vttEscaper.Replace(line.Text))
+		}
+		for i, word := range line.Words {
+			if i > 0 {
+				text.WriteString(" ")
+			}
+			// Timestamps must come after the start of the cue.
+			if word.Start > line.Start {
+				fmt.Fprintf(&text, "<%s>", formatTimestamp(word.Start, "."))
+			}
+			fmt.Fprintf(&text, "<c>%s</c>", vttEscaper.Replace(newlines.Replace(word.Text)))
+		}
+		text.WriteString("\n\n")
+	}
+	_, err := io.WriteString(w, text.String())
+	return err
+}
+
+// ReadWebVTT reads lyrics from WebVTT subtitles. Cue settings and tags
+// are removed, the lines of a cue are joined, and malformed cues are skipped.
+// Cues with timestamp or <c> tags have their words read, one per tag.
+// It fails with ErrFormat when r does not start with a WEBVTT header.
+func ReadWebVTT(r io.Reader) (golyrics.SyncedLyrics, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return golyrics.SyncedLyrics{}, err
+	}
+	found := blocks(string(data))
+	if len(found) == 0 || !strings.HasPrefix(found[0][0], "WEBVTT") {
+		return golyrics.SyncedLyrics{}, fmt.Errorf("%w: no WEBVTT header", ErrFormat)
+	}
+	var lyrics golyrics.SyncedLyrics
+	for _, block := range found[1:] {
+		// The timing line follows the identifier of the cue, which may be missing.
+		// Blocks without one, like NOTE, STYLE or REGION blocks, are skipped.
+		for i := 0; i < len(block) && i < 2; i++ {
+			start, end, ok := parseTiming(block[i])
+			if !ok {
+				continue
+			}
+			line := golyrics.SyncedLine{Start: start, End: end}
+			line.Text, line.Words = parseCueText(strings.Join(block[i+1:], " "), start)
+			endWords(&line)
+			lyrics.Lines = append(lyrics.Lines, line)
+			break
+		}
+	}
+	return sorted(lyrics), nil
+}
+
+// parseCueText returns the text of a WebVTT cue starting at start,
+// and its words when it has timestamp or <c> tags.
+func parseCueText(payload string, start time.Duration) (string, []golyrics.SyncedWord) {
+	var text, word strings.Builder
+	var words []golyrics.SyncedWord
+	at, timed := start, false
+	flush := func() {
+		if w := strings.TrimSpace(html.UnescapeString(word.String())); w != "" {
+			words = append(words, golyrics.SyncedWord{Start: at, Text: w})
+		}
+		word.Reset()
+	}
+	last := 0
+	for _, match := range vttTagPattern.FindAllStringSubmatchIndex(payload, -1) {
+		text.WriteString(payload[last:match[0]])
+		word.WriteString(payload[last:match[0]])
+		last = match[1]
+		tag := payload[match[2]:match[3]]
+		if t, ok := parseTimestamp(tag); ok {
+			flush()
+			at, timed = t, true
+			continue
+		}
+		if tag == "c" || tag == "/c" || strings.HasPrefix(tag, "c.") {
+			// Class spans delimit words even without timestamps.
+			flush()
+			timed = true
+		}
+	}
+	text.WriteString(payload[last:])
+	word.WriteString(payload[last:])
+	flush()
+	if !timed {
+		words = nil
+	}
+	return strings.TrimSpace(html.UnescapeString(text.String())), words
+}
@@ -0,0 +1,115 @@
+package subtitle
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mamal72/golyrics"
+)
+
+func TestWriteWebVTT(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		want    string
+	}{
+		{
+			name:    "should write cues with their settings",
+			options: Options{CueSettings: "line:85% align:center"},
+			want: "WEBVTT\n\n" +
+				"00:00:12.000 --> 00:00:15.000 line:85% align:center\nPain\n\n" +
+				"00:00:15.000 --> 00:00:17.000 line:85% align:center\nCan't run &amp; &lt;hide&gt;\n\n" +
+				"00:00:20.000 --> 00:00:21.000 line:85% align:center\nGo away\n\n" +
+				"01:02:03.004 --> 01:02:03.504 line:85% align:center\nLate\n\n",
+		},
+		{
+			name:    "should write the times of words",
+			options: Options{Words: true},
+			want: "WEBVTT\n\n" +
+				"00:00:12.000 --> 00:00:15.000\n<c>Pain</c>\n\n" +
+				"00:00:15.000 --> 00:00:17.000\n<c>Can't</c> <00:00:15.500><c>run</c> <00:00:16.200><c>&amp;</c> <00:00:16.400><c>&lt;hide&gt;</c>\n\n" +
+				"00:00:20.000 --> 00:00:21.000\n<c>Go</c> <00:00:20.500><c>away</c>\n\n" +
+				"01:02:03.004 --> 01:02:03.504\n<c>Late</c>\n\n",
+		},
+	}
+	for _, tt := range tests {
+		var document strings.Builder
+		if err := WriteWebVTT(&document, pain, tt.options); err != nil {
+			t.Errorf("%q. WriteWebVTT() error = %v", tt.name, err)
+			continue
+		}
+		if document.String() != tt.want {
+			t.Errorf("%q. WriteWebVTT() wrote %q, want %q", tt.name, document.String(), tt.want)
+		}
+	}
+}
+
+func TestReadWebVTT(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     golyrics.SyncedLyrics
+		wantErr  error
+	}{
+		{
+			name: "should read cues, skipping other blocks",
+			document: "WEBVTT - Pain\nKind: captions\n\nNOTE written by hand\n\nSTYLE\n::cue { color: white }\n\n" +
+				"chorus\n00:15.000 --> 00:17.500 align:start\n<v Singer>Can't <i>run</i></v>\n&lt;away&gt; &amp; &nbsp;\n\n" +
+				"00:00:12.000 --> 00:00:15.000\nPain",
+			want: golyrics.SyncedLyrics{Lines: []golyrics.SyncedLine{
+				{Start: 12 * time.Second, End: 15 * time.Second, Text: "Pain"},
+				{Start: 15 * time.Second, End: 17500 * time.Millisecond, Text: "Can't run <away> &"},
+			}},
+		},
+		{
+			name:     "should read the times of words",
+			document: "WEBVTT\n\n00:00:15.000 --> 00:00:17.000\nCan't <00:00:15.500><c.red>run</c> <00:00:16.000><c>away</c>\n",
+			want: golyrics.SyncedLyrics{Lines: []golyrics.SyncedLine{{
+				Start: 15 * time.Second,
+				End:   17 * time.Second,
+				Text:  "Can't run away",
+				Words: []golyrics.SyncedWord{
+					{Start: 15 * time.Second, End: 15500 * time.Millisecond, Text: "Can't"},
+					{Start: 15500 * time.Millisecond, End: 16 * time.Second, Text: "run"},
+					{Start: 16 * time.Second, End: 17 * time.Second, Text: "away"},
+				},
+			}}},
+		},
+		{
+			name:     "should read words in class spans without times",
+			document: "WEBVTT\n\n00:00:15.000 --> 00:00:18.000\n<c>one</c> <c>two</c> <c>three</c>\n",
+			want: golyrics.SyncedLyrics{Lines: []golyrics.SyncedLine{{
+				Start: 15 * time.Second,
+				End:   18 * time.Second,
+				Text:  "one two three",
+				Words: []golyrics.SyncedWord{
+					{Start: 15 * time.Second, End: 18 * time.Second, Text: "one"},
+					{Start: 15 * time.Second, End: 18 * time.Second, Text: "two"},
+					{Start: 15 * time.Second, End: 18 * time.Second, Text: "three"},
+				},
+			}}},
+		},
+		{
+			name:     "should skip malformed cues",
+			document: "WEBVTT\n\n00:00:12.000 --> later\nPain\n\n00:00:20.000 -->\nGo away",
+		},
+		{
+			name:     "should fail without a WEBVTT header",
+			document: "1\n00:00:12,000 --> 00:00:15,000\nPain",
+			wantErr:  ErrFormat,
+		},
+	}
+	for _, tt := range tests {
+		got, err := ReadWebVTT(strings.NewReader(tt.document))
+		if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+			t.Errorf("%q. ReadWebVTT() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. ReadWebVTT() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
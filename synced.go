@@ -0,0 +1,146 @@
+package golyrics
+
+import (
+	"strings"
+	"time"
+)
+
+// interpolatedWordDuration is how long words are assumed to last
+// when the end of their line is not known.
+const interpolatedWordDuration = 500 * time.Millisecond
+
+// SyncedLyrics are lyrics timed line by line, and maybe word by word,
+// so that players can highlight them as they are sung.
+type SyncedLyrics struct {
+	// Lines holds the lines, sorted by start time. Lines without text
+	// mark the end of the previous line, such as before an instrumental break.
+	Lines []SyncedLine
+}
+
+// SyncedLine is a timed line of lyrics.
+type SyncedLine struct {
+	Start time.Duration
+	// End is when the line ends, or zero when it is not known.
+	End  time.Duration
+	Text string
+	// Words holds the timed words of the line, when they are known.
+	Words []SyncedWord
+}
+
+// SyncedWord is a timed word of a line.
+type SyncedWord struct {
+	Start time.Duration
+	// End is when the word ends, or zero when it is not known.
+	End  time.Duration
+	Text string
+}
+
+// Interpolate returns a copy of s where every line and word has an end,
+// and every line has its words. Lines end when the next one starts,
+// and the last one at length, when it is known and after its start.
+// Lines without word times have their duration split evenly
+// between their words, and words without an end end when the next starts.
+func (s SyncedLyrics) Interpolate(length time.Duration) SyncedLyrics {
+	s = s.clone()
+	for i := range s.Lines {
+		line := &s.Lines[i]
+		if line.End <= line.Start {
+			switch {
+			case i+1 < len(s.Lines) && s.Lines[i+1].Start > line.Start:
+				line.End = s.Lines[i+1].Start
+			case i+1 == len(s.Lines) && length > line.Start:
+				line.End = length
+			default:
+				words := len(strings.Fields(line.Text))
+				if len(line.Words) > 0 {
+					words = len(line.Words)
+				}
+				line.End = line.Start + time.Duration(words)*interpolatedWordDuration
+			}
+		}
+		if len(line.Words) == 0 {
+			line.Words = spreadWords(line.Text, line.Start, line.End)
+			continue
+		}
+		for j := range line.Words {
+			word := &line.Words[j]
+			if word.End > word.Start {
+				continue
+			}
+			word.End = line.End
+			if j+1 < len(line.Words) && line.Words[j+1].Start > word.Start {
+				word.End = line.Words[j+1].Start
+			}
+		}
+	}
+	return s
+}
+
+// spreadWords splits the time from start to end evenly between the words of text.
+func spreadWords(text string, start, end time.Duration) []SyncedWord {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil
+	}
+	words := make([]SyncedWord, len(fields))
+	step := (end - start) / time.Duration(len(fields))
+	for i, field := range fields {
+		words[i] = SyncedWord{Start: start + time.Duration(i)*step, End: start + time.Duration(i+1)*step, Text: field}
+	}
+	words[len(words)-1].End = end
+	return words
+}
+
+// At returns the indexes of the line and the word sung at t,
+// or -1 when there is none. Use it on interpolated lyrics
+// to know the end of every line and word.
+func (s SyncedLyrics) At(t time.Duration) (line, word int) {
+	line, word = -1, -1
+	for i, l := range s.Lines {
+		if l.Start > t {
+			break
+		}
+		if l.End <= l.Start || t < l.End {
+			line = i
+		} else {
+			line = -1
+		}
+	}
+	if line < 0 {
+		return -1, -1
+	}
+	for j, w := range s.Lines[line].Words {
+		if w.Start > t {
+			break
+		}
+		if w.End <= w.Start || t < w.End {
+			word = j
+		} else {
+			word = -1
+		}
+	}
+	return line, word
+}
+
+// String returns the text of the lines, one per line.
+func (s SyncedLyrics) String() string {
+	lines := make([]string, len(s.Lines))
+	for i, line := range s.Lines {
+		lines[i] = line.Text
+	}
+	return strings.Join(lines, "\n")
+}
+
+// clone returns a copy of s that shares no memory with it.
+func (s SyncedLyrics) clone() SyncedLyrics {
+	if s.Lines == nil {
+		return s
+	}
+	lines := make([]SyncedLine, len(s.Lines))
+	for i, line := range s.Lines {
+		line.Words = append([]SyncedWord(nil), line.Words...)
+		lines[i] = line
+	}
+	s.Lines = lines
+	return s
+}
@@ -0,0 +1,157 @@
+package golyrics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSyncedLyrics_Interpolate(t *testing.T) {
+	tests := []struct {
+		name   string
+		lyrics SyncedLyrics
+		length time.Duration
+		want   SyncedLyrics
+	}{
+		{
+			name: "should spread lines evenly between their words",
+			lyrics: SyncedLyrics{Lines: []SyncedLine{
+				{Start: 10 * time.Second, Text: "Pain  can't run"},
+				{Start: 13 * time.Second},
+				{Start: 20 * time.Second, Text: "Away"},
+			}},
+			length: 22 * time.Second,
+			want: SyncedLyrics{Lines: []SyncedLine{
+				{
+					Start: 10 * time.Second,
+					End:   13 * time.Second,
+					Text:  "Pain  can't run",
+					Words: []SyncedWord{
+						{Start: 10 * time.Second, End: 11 * time.Second, Text: "Pain"},
+						{Start: 11 * time.Second, End: 12 * time.Second, Text: "can't"},
+						{Start: 12 * time.Second, End: 13 * time.Second, Text: "run"},
+					},
+				},
+				{Start: 13 * time.Second, End: 20 * time.Second},
+				{
+					Start: 20 * time.Second,
+					End:   22 * time.Second,
+					Text:  "Away",
+					Words: []SyncedWord{{Start: 20 * time.Second, End: 22 * time.Second, Text: "Away"}},
+				},
+			}},
+		},
+		{
+			name: "should end timed words at the next word or the end of their line",
+			lyrics: SyncedLyrics{Lines: []SyncedLine{
+				{
+					Start: 10 * time.Second,
+					Text:  "Go away",
+					Words: []SyncedWord{
+						{Start: 10 * time.Second, End: 10500 * time.Millisecond, Text: "Go"},
+						{Start: 11 * time.Second, Text: "away"},
+					},
+				},
+				{Start: 14 * time.Second, End: 15 * time.Second, Text: "Pain"},
+			}},
+			want: SyncedLyrics{Lines: []SyncedLine{
+				{
+					Start: 10 * time.Second,
+					End:   14 * time.Second,
+					Text:  "Go away",
+					Words: []SyncedWord{
+						{Start: 10 * time.Second, End: 10500 * time.Millisecond, Text: "Go"},
+						{Start: 11 * time.Second, End: 14 * time.Second, Text: "away"},
+					},
+				},
+				{
+					Start: 14 * time.Second,
+					End:   15 * time.Second,
+					Text:  "Pain",
+					Words: []SyncedWord{{Start: 14 * time.Second, End: 15 * time.Second, Text: "Pain"}},
+				},
+			}},
+		},
+		{
+			name: "should give words a default duration when the end of their line is unknown",
+			lyrics: SyncedLyrics{Lines: []SyncedLine{
+				{Start: 10 * time.Second, Text: "Same time"},
+				{Start: 10 * time.Second, Text: "Can't run"},
+			}},
+			want: SyncedLyrics{Lines: []SyncedLine{
+				{
+					Start: 10 * time.Second,
+					End:   11 * time.Second,
+					Text:  "Same time",
+					Words: []SyncedWord{
+						{Start: 10 * time.Second, End: 10500 * time.Millisecond, Text: "Same"},
+						{Start: 10500 * time.Millisecond, End: 11 * time.Second, Text: "time"},
+					},
+				},
+				{
+					Start: 10 * time.Second,
+					End:   11 * time.Second,
+					Text:  "Can't run",
+					Words: []SyncedWord{
+						{Start: 10 * time.Second, End: 10500 * time.Millisecond, Text: "Can't"},
+						{Start: 10500 * time.Millisecond, End: 11 * time.Second, Text: "run"},
+					},
+				},
+			}},
+		},
+		{
+			name: "should keep empty lyrics empty",
+			want: SyncedLyrics{},
+		},
+	}
+	for _, tt := range tests {
+		original := tt.lyrics.clone()
+		if got := tt.lyrics.Interpolate(tt.length); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. SyncedLyrics.Interpolate() = %#v, want %#v", tt.name, got, tt.want)
+		}
+		if !reflect.DeepEqual(tt.lyrics, original) {
+			t.Errorf("%q. SyncedLyrics.Interpolate() changed the lyrics to %#v", tt.name, tt.lyrics)
+		}
+	}
+}
+
+func TestSyncedLyrics_At(t *testing.T) {
+	lyrics := SyncedLyrics{Lines: []SyncedLine{
+		{Start: 10 * time.Second, Text: "Pain"},
+		{Start: 13 * time.Second},
+		{
+			Start: 20 * time.Second,
+			End:   23 * time.Second,
+			Text:  "Go away",
+			Words: []SyncedWord{
+				{Start: 20 * time.Second, End: 20500 * time.Millisecond, Text: "Go"},
+				{Start: 21 * time.Second, Text: "away"},
+			},
+		},
+	}}
+	tests := []struct {
+		at       time.Duration
+		wantLine int
+		wantWord int
+	}{
+		{at: 5 * time.Second, wantLine: -1, wantWord: -1},
+		{at: 10 * time.Second, wantLine: 0, wantWord: -1},
+		{at: 15 * time.Second, wantLine: 1, wantWord: -1},
+		{at: 20 * time.Second, wantLine: 2, wantWord: 0},
+		{at: 20700 * time.Millisecond, wantLine: 2, wantWord: -1},
+		{at: 22 * time.Second, wantLine: 2, wantWord: 1},
+		{at: 23 * time.Second, wantLine: -1, wantWord: -1},
+	}
+	for _, tt := range tests {
+		if line, word := lyrics.At(tt.at); line != tt.wantLine || word != tt.wantWord {
+			t.Errorf("%v. SyncedLyrics.At() = %d, %d, want %d, %d", tt.at, line, word, tt.wantLine, tt.wantWord)
+		}
+	}
+}
+
+func TestSyncedLyrics_String(t *testing.T) {
+	lyrics := SyncedLyrics{Lines: []SyncedLine{{Text: "Pain"}, {}, {Text: "Can't run"}}}
+	if got, want := lyrics.String(), "Pain\n\nCan't run"; got != want {
+		t.Errorf("SyncedLyrics.String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,51 @@
+package golyrics
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// wikiTitle returns the normalized title of the lyrics wiki page
+// of the song name by artist.
+func wikiTitle(artist, name string) string {
+	return normalizeTitle(artist) + ":" + normalizeTitle(name)
+}
+
+// normalizeTitle normalizes a page title like MediaWiki does:
+// it is put in Unicode normalization form C, runs of spaces and underscores
+// become a single underscore, leading and trailing ones are removed,
+// and the first letter is capitalized.
+func normalizeTitle(title string) string {
+	title = norm.NFC.String(title)
+	words := strings.FieldsFunc(title, func(r rune) bool {
+		return r == '_' || unicode.IsSpace(r)
+	})
+	title = strings.Join(words, "_")
+	first, size := utf8.DecodeRuneInString(title)
+	if first == utf8.RuneError {
+		return title
+	}
+	return string(unicode.ToUpper(first)) + title[size:]
+}
+
+// escapeTitle percent-encodes a normalized title for use in a URL path,
+// leaving the characters MediaWiki leaves readable in its own URLs as is.
+func escapeTitle(title string) string {
+	const hex = "0123456789ABCDEF"
+	var escaped strings.Builder
+	for i := 0; i < len(title); i++ {
+		c := title[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+			strings.IndexByte("-._~;@$!*(),/:", c) >= 0 {
+			escaped.WriteByte(c)
+			continue
+		}
+		escaped.WriteByte('%')
+		escaped.WriteByte(hex[c>>4])
+		escaped.WriteByte(hex[c&15])
+	}
+	return escaped.String()
+}
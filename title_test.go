@@ -0,0 +1,70 @@
+package golyrics
+
+import "testing"
+
+func Test_wikiTitle(t *testing.T) {
+	type args struct {
+		artist string
+		name   string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "test should replace spaces with underscores",
+			args: args{"Sandra Boynton", "The Shortest Song In The Universe"},
+			want: "Sandra_Boynton:The_Shortest_Song_In_The_Universe",
+		},
+		{
+			name: "test should collapse and trim spaces and underscores",
+			args: args{"  Sandra __ Boynton ", "_The  Shortest_Song_"},
+			want: "Sandra_Boynton:The_Shortest_Song",
+		},
+		{
+			name: "test should capitalize the first letter of artist and name",
+			args: args{"blackfield", "émigré"},
+			want: "Blackfield:Émigré",
+		},
+		{
+			name: "test should compose decomposed characters",
+			args: args{"Sigur Ro\u0301s", "Hoppi\u0301polla"},
+			want: "Sigur_R\u00f3s:Hopp\u00edpolla",
+		},
+	}
+	for _, tt := range tests {
+		if got := wikiTitle(tt.args.artist, tt.args.name); got != tt.want {
+			t.Errorf("%q. wikiTitle() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func Test_escapeTitle(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{
+			name:  "test should keep readable characters",
+			title: "AC/DC:Who_Made_Who_(Live),_Vol._1!",
+			want:  "AC/DC:Who_Made_Who_(Live),_Vol._1!",
+		},
+		{
+			name:  "test should escape reserved characters",
+			title: "Question_Mark:96_Tears?_#1_&_100%_+_=",
+			want:  "Question_Mark:96_Tears%3F_%231_%26_100%25_%2B_%3D",
+		},
+		{
+			name:  "test should escape non-ASCII characters",
+			title: "Sigur_Rós:Hoppípolla",
+			want:  "Sigur_R%C3%B3s:Hopp%C3%ADpolla",
+		},
+	}
+	for _, tt := range tests {
+		if got := escapeTitle(tt.title); got != tt.want {
+			t.Errorf("%q. escapeTitle() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
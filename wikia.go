@@ -0,0 +1,400 @@
+package golyrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/buger/jsonparser"
+)
+
+const wikiaProviderName = "wikia"
+
+const searchBaseURI = "http://lyrics.wikia.com/index.php?action=ajax&rs=getLinkSuggest&format=json&query="
+const lyricsBaseURI = "http://lyrics.wikia.com/wiki/"
+
+// licensingNotice is shown instead of the lyrics
+// of songs the wiki is not licensed to display.
+const licensingNotice = "we are not licensed to display the full lyrics"
+
+// maxRedirects is the number of redirects followed to reach a lyrics page.
+const maxRedirects = 5
+
+// redirectPattern matches the wikitext of a redirect page.
+var redirectPattern = regexp.MustCompile(`(?i)#REDIRECT\s*:?\s*\[\[([^\]|#]+)`)
+
+// disambiguationSelector matches the marks of a disambiguation page.
+const disambiguationSelector = "#disambig, .disambig, .disambiguation, a[href*='Category:Disambiguation']"
+
+// songHeaderSelector matches the header rendered above the lyrics
+// by the song templates, and creditsSelector the credits below them.
+const (
+	songHeaderSelector = "#song-header-container, #song-header-title, .song-header"
+	creditsSelector    = "#song-credits, .song-credits, .song-credit-box"
+)
+
+var (
+	// albumTitlePattern matches the name of album pages, like "Album (2004)".
+	albumTitlePattern = regexp.MustCompile(`^(.+?) \((\d{4})\)$`)
+	// creditPattern matches a "Label: value" line of the credits.
+	creditPattern = regexp.MustCompile(`(?i)^\s*(featuring|feat\.?|songwriters?|written by|writers?|composers?|lyricists?|music|lyrics|language|track(?: number| no\.?| #)?)\s*:\s*(.+?)\s*$`)
+	// namesPattern matches the separators of a list of names.
+	namesPattern  = regexp.MustCompile(`\s*(?:[,;/&]|\band\b)\s*`)
+	numberPattern = regexp.MustCompile(`\d+`)
+)
+
+// namespaces holds the title prefixes of wiki pages that are not songs.
+var namespaces = map[string]bool{
+	"Category": true, "File": true, "Help": true, "Image": true,
+	"LyricWiki": true, "Media": true, "MediaWiki": true, "Special": true,
+	"Talk": true, "Template": true, "User": true, "User_talk": true,
+}
+
+// The media types accepted for search results and lyrics pages.
+var (
+	searchContentTypes = []string{"application/json", "text/javascript", "application/javascript"}
+	pageContentTypes   = []string{"text/html", "application/xhtml+xml"}
+)
+
+// wikiaProvider scrapes the LyricWiki pages hosted on Wikia.
+type wikiaProvider struct {
+	client        *Client
+	searchBaseURI string
+	lyricsBaseURI string
+	formatting    bool
+}
+
+func newWikiaProvider(c *Client) Provider {
+	return &wikiaProvider{
+		client:        c,
+		searchBaseURI: c.searchBaseURI,
+		lyricsBaseURI: c.lyricsBaseURI,
+		formatting:    c.formatting,
+	}
+}
+
+func (p *wikiaProvider) Name() string {
+	return wikiaProviderName
+}
+
+func (p *wikiaProvider) cacheID() string {
+	return fmt.Sprintf("%s(%s,%s,%t)", wikiaProviderName, p.searchBaseURI, p.lyricsBaseURI, p.formatting)
+}
+
+func (p *wikiaProvider) Search(ctx context.Context, query string) ([]Track, error) {
+	URI := getSearchURI(p.searchBaseURI, query)
+	data, err := p.client.get(ctx, URI, searchContentTypes...)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	suggestions := []Track{}
+	var parseErr error
+	err = jsonparser.ArrayEach(data, func(value []byte, _ jsonparser.ValueType, offset int, err error) {
+		if err != nil {
+			parseErr = err
+		}
+		if parseErr != nil || ctx.Err() != nil {
+			return
+		}
+		title := string(value)
+		trackParts := strings.SplitN(title, ":", 2)
+		if len(trackParts) < 2 {
+			return
+		}
+		track := Track{
+			Artist: trackParts[0],
+			Name:   trackParts[1],
+		}
+		suggestions = append(suggestions, track)
+	}, "suggestions")
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err == nil {
+		err = parseErr
+	}
+	if err != nil {
+		return nil, &ParseError{URL: URI, Err: err}
+	}
+
+	return suggestions, nil
+}
+
+func (p *wikiaProvider) Fetch(ctx context.Context, track *Track) error {
+	title := wikiTitle(track.Artist, track.Name)
+	visited := []string{}
+	for {
+		visited = append(visited, title)
+		URI := p.lyricsBaseURI + escapeTitle(title)
+		doc, err := p.page(ctx, URI)
+		if err != nil {
+			return err
+		}
+
+		if target := redirectTarget(doc); target != "" {
+			// The target is fetched again on every revalidation,
+			// as the redirect can stay the same while it changes.
+			if r := revalidationFrom(ctx); r != nil {
+				r.forget(URI)
+			}
+			title = normalizeWikiTitle(target)
+			if len(visited) > maxRedirects || contains(visited, title) {
+				return fmt.Errorf("%w: %s", ErrTooManyRedirects, strings.Join(append(visited, title), " -> "))
+			}
+			continue
+		}
+		if isDisambiguation(doc) {
+			return &AmbiguousError{Title: title, Candidates: linkedTracks(doc, title)}
+		}
+
+		licensed := !strings.Contains(doc.Text(), licensingNotice)
+		lyricbox := doc.Find(".lyricbox")
+		if lyricbox.Length() == 0 {
+			if !licensed {
+				return ErrNotLicensed
+			}
+			return ErrNotFound
+		}
+		lyrics := extractLyrics(lyricbox.Get(0), false)
+		if err := classifyLyrics(lyrics, licensed); err != nil {
+			return err
+		}
+		if p.formatting {
+			lyrics = extractLyrics(lyricbox.Get(0), true)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		canonical, ok := canonicalTitle(doc)
+		if !ok && len(visited) > 1 {
+			canonical, ok = title, true
+		}
+		if ok {
+			if artist, name, ok := splitTitle(canonical); ok {
+				track.Artist, track.Name = artist, name
+			}
+		}
+		track.Lyrics = lyrics
+		track.URL = pageURL(doc, URI)
+		readSongHeader(doc, track)
+		readCredits(doc, track)
+		return nil
+	}
+}
+
+// page fetches and parses the wiki page at URI.
+func (p *wikiaProvider) page(ctx context.Context, URI string) (*goquery.Document, error) {
+	page, err := p.client.get(ctx, URI, pageContentTypes...)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
+	if err != nil {
+		return nil, &ParseError{URL: URI, Err: err}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// redirectTarget returns the title a redirect page points to,
+// or an empty string if doc is not a redirect.
+// Rendered redirects link to their target from a .redirectText list,
+// while raw ones only hold the #REDIRECT [[Target]] wikitext.
+func redirectTarget(doc *goquery.Document) string {
+	if href, ok := doc.Find(".redirectText a[href], .redirectMsg a[href]").First().Attr("href"); ok {
+		if title, ok := hrefTitle(href); ok {
+			return title
+		}
+	}
+	if match := redirectPattern.FindStringSubmatch(doc.Text()); match != nil {
+		return strings.TrimSpace(match[1])
+	}
+	return ""
+}
+
+// isDisambiguation reports whether doc is a disambiguation page,
+// marked with the disambiguation template or category.
+func isDisambiguation(doc *goquery.Document) bool {
+	return doc.Find(disambiguationSelector).Length() > 0
+}
+
+// linkedTracks returns the songs linked from the content of doc,
+// other than the page titled self.
+func linkedTracks(doc *goquery.Document, self string) []Track {
+	content := doc.Find("#mw-content-text")
+	if content.Length() == 0 {
+		content = doc.Find("body")
+	}
+	tracks := []Track{}
+	seen := map[string]bool{self: true}
+	content.Find("a[href]").Each(func(_ int, link *goquery.Selection) {
+		href, _ := link.Attr("href")
+		title, ok := hrefTitle(href)
+		if !ok {
+			return
+		}
+		title = normalizeWikiTitle(title)
+		artist, name, ok := splitTitle(title)
+		if !ok || seen[title] {
+			return
+		}
+		seen[title] = true
+		tracks = append(tracks, Track{Artist: artist, Name: name})
+	})
+	return tracks
+}
+
+// canonicalTitle returns the title from the canonical link of doc,
+// which names the page that was finally served.
+func canonicalTitle(doc *goquery.Document) (string, bool) {
+	href, ok := doc.Find("link[rel=canonical]").Attr("href")
+	if !ok {
+		return "", false
+	}
+	title, ok := hrefTitle(href)
+	if !ok {
+		return "", false
+	}
+	return normalizeWikiTitle(title), true
+}
+
+// pageURL returns the canonical URL of doc, fetched from URI.
+func pageURL(doc *goquery.Document, URI string) string {
+	href, ok := doc.Find("link[rel=canonical]").Attr("href")
+	if !ok {
+		return URI
+	}
+	base, err := url.Parse(URI)
+	if err != nil {
+		return URI
+	}
+	canonical, err := base.Parse(href)
+	if err != nil {
+		return URI
+	}
+	return canonical.String()
+}
+
+// readSongHeader sets the album, year and featured artists of track
+// from the links of the header rendered above the lyrics, which reads like
+// "This song is by Artist featuring Other and appears on the album Album (2004)".
+func readSongHeader(doc *goquery.Document, track *Track) {
+	artists := 0
+	doc.Find(songHeaderSelector).First().Find("a[href]").Each(func(_ int, link *goquery.Selection) {
+		href, _ := link.Attr("href")
+		title, ok := hrefTitle(href)
+		if !ok {
+			return
+		}
+		title = normalizeWikiTitle(title)
+		if _, album, ok := splitTitle(title); ok {
+			if match := albumTitlePattern.FindStringSubmatch(album); match != nil {
+				track.Album = match[1]
+				track.Year, _ = strconv.Atoi(match[2])
+			}
+			return
+		}
+		if strings.Contains(title, ":") {
+			return
+		}
+		if artists++; artists > 1 {
+			track.addFeaturing(strings.Replace(title, "_", " ", -1))
+		}
+	})
+}
+
+// readCredits sets the writers, featured artists, language and track number
+// of track from the "Label: value" lines of the credits below the lyrics.
+func readCredits(doc *goquery.Document, track *Track) {
+	doc.Find(creditsSelector).Each(func(_ int, credits *goquery.Selection) {
+		for _, line := range strings.Split(extractLyrics(credits.Get(0), false), "\n") {
+			match := creditPattern.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			label, value := strings.ToLower(match[1]), match[2]
+			switch {
+			case strings.HasPrefix(label, "feat"):
+				for _, name := range namesPattern.Split(value, -1) {
+					track.addFeaturing(name)
+				}
+			case label == "language":
+				track.Language = value
+			case strings.HasPrefix(label, "track"):
+				if number := numberPattern.FindString(value); number != "" {
+					track.TrackNumber, _ = strconv.Atoi(number)
+				}
+			default:
+				for _, name := range namesPattern.Split(value, -1) {
+					track.addWriter(name)
+				}
+			}
+		}
+	})
+}
+
+// appendName appends name to names, unless it is empty or already there.
+func appendName(names []string, name string) []string {
+	if name == "" || contains(names, name) {
+		return names
+	}
+	return append(names, name)
+}
+
+// hrefTitle returns the title of the wiki page linked by href.
+func hrefTitle(href string) (string, bool) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+	i := strings.Index(u.Path, "/wiki/")
+	if i < 0 {
+		return "", false
+	}
+	title := u.Path[i+len("/wiki/"):]
+	return title, title != ""
+}
+
+// normalizeWikiTitle normalizes both parts of an Artist:Name title.
+func normalizeWikiTitle(title string) string {
+	parts := strings.SplitN(title, ":", 2)
+	if len(parts) < 2 {
+		return normalizeTitle(title)
+	}
+	return wikiTitle(parts[0], parts[1])
+}
+
+// splitTitle splits a normalized song title into its artist and name,
+// failing for titles that are not songs.
+func splitTitle(title string) (artist, name string, ok bool) {
+	parts := strings.SplitN(title, ":", 2)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" || namespaces[parts[0]] {
+		return "", "", false
+	}
+	return strings.Replace(parts[0], "_", " ", -1), strings.Replace(parts[1], "_", " ", -1), true
+}
+
+func contains(titles []string, title string) bool {
+	for _, t := range titles {
+		if t == title {
+			return true
+		}
+	}
+	return false
+}
+
+func getSearchURI(baseURI, query string) string {
+	return fmt.Sprintf("%s%s", baseURI, url.QueryEscape(query))
+}
@@ -0,0 +1,220 @@
+package golyrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_getSearchURI(t *testing.T) {
+	type args struct {
+		query string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "test should return right URI",
+			args: args{
+				"blackfield:pain",
+			},
+			want: "http://lyrics.wikia.com/index.php?action=ajax&rs=getLinkSuggest&format=json&query=blackfield%3Apain",
+		},
+	}
+	for _, tt := range tests {
+		if got := getSearchURI(searchBaseURI, tt.args.query); got != tt.want {
+			t.Errorf("%q. getSearchURI() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func newRedirectTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch title := r.URL.Path[len("/wiki/"):]; title {
+		case "Blackfield:Pain":
+			fmt.Fprint(w, `<html><head><link rel="canonical" href="http://lyrics/wiki/Blackfield:Pain"></head>`+
+				`<body><div class='lyricbox'>Pain</div></body></html>`)
+		case "Blackfield:Once":
+			fmt.Fprint(w, `<html><body><div id="song-header-container"><div id="song-header-title"><b>"Once"</b><br>`+
+				`This song is by <a href="/wiki/Blackfield">Blackfield</a> featuring <a href="/wiki/Aviv_Geffen">Aviv Geffen</a> `+
+				`and appears on the album <a href="/wiki/Blackfield:Blackfield_II_(2007)">Blackfield II (2007)</a>.</div></div>`+
+				`<div class='lyricbox'>Once</div><div class="song-credit-box"><p>Songwriters: Steven Wilson, Aviv Geffen</p>`+
+				`<p>Language: English</p><p>Track #: 2</p><p>Publishers: Nobody</p></div></body></html>`)
+		case "Sigur_Rós:Hoppípolla":
+			fmt.Fprint(w, `<html><body><div class='lyricbox'>Brosandi</div></body></html>`)
+		case "Old:Name":
+			fmt.Fprint(w, `<html><body><div class="redirectMsg"><p>Redirect to:</p><ul class="redirectText">`+
+				`<li><a href="/wiki/Blackfield:Pain" title="Blackfield:Pain">Blackfield:Pain</a></li></ul></div></body></html>`)
+		case "Raw:Redirect":
+			fmt.Fprint(w, `<html><body><pre>#REDIRECT [[Sigur Rós:hoppípolla]]</pre></body></html>`)
+		case "Served:Elsewhere":
+			fmt.Fprint(w, `<html><head><link rel="canonical" href="/wiki/Real_Artist:Real_Song"></head>`+
+				`<body><div class='lyricbox'>Real</div></body></html>`)
+		case "Loop:A":
+			fmt.Fprint(w, `<pre>#REDIRECT [[Loop:B]]</pre>`)
+		case "Loop:B":
+			fmt.Fprint(w, `<pre>#REDIRECT [[Loop:A]]</pre>`)
+		case "Pain:Songs":
+			fmt.Fprint(w, `<html><body><div id="mw-content-text"><p>Pain may refer to:</p><ul>`+
+				`<li><a href="/wiki/Blackfield:Pain">Blackfield</a></li>`+
+				`<li><a href="/wiki/Three_Days_Grace:Pain">Three Days Grace</a></li>`+
+				`<li><a href="/wiki/Three_Days_Grace:Pain#Lyrics">Three Days Grace</a></li>`+
+				`<li><a href="/index.php?title=Nobody:Pain&action=edit&redlink=1">Nobody</a></li>`+
+				`</ul><a href="/wiki/Category:Disambiguation">Disambiguation</a></div></body></html>`)
+		default:
+			var hop int
+			if _, err := fmt.Sscanf(title, "Hop:%d", &hop); err == nil {
+				fmt.Fprintf(w, `<pre>#REDIRECT [[Hop:%d]]</pre>`, hop+1)
+				return
+			}
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestWikiaProvider_FetchRedirects(t *testing.T) {
+	server := newRedirectTestServer()
+	defer server.Close()
+	provider := newWikiaProvider(NewClient(
+		WithLyricsBaseURI(server.URL+"/wiki/"),
+		WithRetryPolicy(RetryPolicy{}),
+	))
+
+	tests := []struct {
+		name    string
+		track   Track
+		want    Track
+		wantErr error
+	}{
+		{
+			name:  "should keep the track as asked when there is no redirect",
+			track: Track{Artist: "Sigur Rós", Name: "hoppípolla"},
+			want:  Track{Artist: "Sigur Rós", Name: "hoppípolla", Lyrics: "Brosandi", URL: "{server}/wiki/Sigur_R%C3%B3s:Hopp%C3%ADpolla"},
+		},
+		{
+			name:  "should follow rendered redirects to the canonical track",
+			track: Track{Artist: "Old", Name: "Name"},
+			want:  Track{Artist: "Blackfield", Name: "Pain", Lyrics: "Pain", URL: "http://lyrics/wiki/Blackfield:Pain"},
+		},
+		{
+			name:  "should follow raw redirects and normalize their target",
+			track: Track{Artist: "Raw", Name: "Redirect"},
+			want:  Track{Artist: "Sigur Rós", Name: "Hoppípolla", Lyrics: "Brosandi", URL: "{server}/wiki/Sigur_R%C3%B3s:Hopp%C3%ADpolla"},
+		},
+		{
+			name:  "should use the canonical title of pages the host redirected to",
+			track: Track{Artist: "Served", Name: "Elsewhere"},
+			want:  Track{Artist: "Real Artist", Name: "Real Song", Lyrics: "Real", URL: "{server}/wiki/Real_Artist:Real_Song"},
+		},
+		{
+			name:  "should read the metadata in the song header and credits",
+			track: Track{Artist: "Blackfield", Name: "Once"},
+			want: Track{
+				Artist:      "Blackfield",
+				Name:        "Once",
+				Lyrics:      "Once",
+				Album:       "Blackfield II",
+				Year:        2007,
+				TrackNumber: 2,
+				Metadata: &TrackMetadata{
+					Featuring: []string{"Aviv Geffen"},
+					Writers:   []string{"Steven Wilson", "Aviv Geffen"},
+				},
+				Language: "English",
+				URL:      "{server}/wiki/Blackfield:Once",
+			},
+		},
+		{
+			name:    "should stop at redirect loops",
+			track:   Track{Artist: "Loop", Name: "A"},
+			want:    Track{Artist: "Loop", Name: "A"},
+			wantErr: ErrTooManyRedirects,
+		},
+		{
+			name:    "should stop after too many redirects",
+			track:   Track{Artist: "Hop", Name: "0"},
+			want:    Track{Artist: "Hop", Name: "0"},
+			wantErr: ErrTooManyRedirects,
+		},
+		{
+			name:    "should fail as ambiguous for disambiguation pages",
+			track:   Track{Artist: "Pain", Name: "Songs"},
+			want:    Track{Artist: "Pain", Name: "Songs"},
+			wantErr: ErrAmbiguous,
+		},
+	}
+	for _, tt := range tests {
+		track := tt.track
+		if err := provider.Fetch(context.Background(), &track); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%q. wikiaProvider.Fetch() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		tt.want.URL = strings.Replace(tt.want.URL, "{server}", server.URL, 1)
+		if !reflect.DeepEqual(track, tt.want) {
+			t.Errorf("%q. wikiaProvider.Fetch() track = %+v, want %+v", tt.name, track, tt.want)
+		}
+	}
+}
+
+func TestWikiaProvider_FetchDisambiguation(t *testing.T) {
+	server := newRedirectTestServer()
+	defer server.Close()
+	provider := newWikiaProvider(NewClient(WithLyricsBaseURI(server.URL + "/wiki/")))
+
+	err := provider.Fetch(context.Background(), &Track{Artist: "Pain", Name: "Songs"})
+	var ambiguous *AmbiguousError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("wikiaProvider.Fetch() error = %v, want an *AmbiguousError", err)
+	}
+	want := &AmbiguousError{
+		Title: "Pain:Songs",
+		Candidates: []Track{
+			{Artist: "Blackfield", Name: "Pain"},
+			{Artist: "Three Days Grace", Name: "Pain"},
+		},
+	}
+	if !reflect.DeepEqual(ambiguous, want) {
+		t.Errorf("wikiaProvider.Fetch() error = %+v, want %+v", ambiguous, want)
+	}
+}
+
+func Test_splitTitle(t *testing.T) {
+	tests := []struct {
+		name       string
+		title      string
+		wantArtist string
+		wantName   string
+		wantOk     bool
+	}{
+		{
+			name:       "should split songs and turn underscores into spaces",
+			title:      "Three_Days_Grace:Pain:_Live",
+			wantArtist: "Three Days Grace",
+			wantName:   "Pain: Live",
+			wantOk:     true,
+		},
+		{
+			name:  "should not split artist pages",
+			title: "Three_Days_Grace",
+		},
+		{
+			name:  "should not split pages of other namespaces",
+			title: "Category:Disambiguation",
+		},
+	}
+	for _, tt := range tests {
+		artist, name, ok := splitTitle(tt.title)
+		if artist != tt.wantArtist || name != tt.wantName || ok != tt.wantOk {
+			t.Errorf("%q. splitTitle() = %q, %q, %v, want %q, %q, %v",
+				tt.name, artist, name, ok, tt.wantArtist, tt.wantName, tt.wantOk)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package wikitext
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// template is a template transclusion, {{name|value|key=value}}.
+// Unnamed parameters are keyed by their position, starting at "1".
+type template struct {
+	name       string
+	params     map[string]string
+	start, end int
+}
+
+var (
+	yearPattern      = regexp.MustCompile(`\b\d{4}\b`)
+	albumYearPattern = regexp.MustCompile(`^(.*?)\s*\((\d{4})\)$`)
+	numberPattern    = regexp.MustCompile(`\d+`)
+	namesPattern     = regexp.MustCompile(`\s*(?:[,;/&\n]|\band\b)\s*`)
+)
+
+// templates returns the outermost templates of source, in order.
+func templates(source string) []template {
+	var found []template
+	for i := 0; i < len(source); {
+		start := strings.Index(source[i:], "{{")
+		if start < 0 {
+			break
+		}
+		start += i
+		end := closingBraces(source, start)
+		if end < 0 {
+			break
+		}
+		t := parseTemplate(source[start+2 : end-2])
+		t.start, t.end = start, end
+		found = append(found, t)
+		i = end
+	}
+	return found
+}
+
+// removeTemplates removes the templates of text.
+func removeTemplates(text string) string {
+	var removed strings.Builder
+	last := 0
+	for _, t := range templates(text) {
+		removed.WriteString(text[last:t.start])
+		last = t.end
+	}
+	removed.WriteString(text[last:])
+	return removed.String()
+}
+
+// closingBraces returns the index right after the braces closing
+// the template starting at start, or -1 if it is not closed.
+func closingBraces(source string, start int) int {
+	depth := 0
+	for i := start; i+1 < len(source); {
+		switch source[i : i+2] {
+		case "{{":
+			depth++
+			i += 2
+		case "}}":
+			depth--
+			i += 2
+			if depth == 0 {
+				return i
+			}
+		default:
+			i++
+		}
+	}
+	return -1
+}
+
+// splitParams splits the inside of a template at the pipes
+// that are not part of a nested template or link.
+func splitParams(inner string) []string {
+	var parts []string
+	depth, last := 0, 0
+	for i := 0; i < len(inner); i++ {
+		switch {
+		case strings.HasPrefix(inner[i:], "{{"), strings.HasPrefix(inner[i:], "[["):
+			depth++
+			i++
+		case strings.HasPrefix(inner[i:], "}}"), strings.HasPrefix(inner[i:], "]]"):
+			if depth > 0 {
+				depth--
+			}
+			i++
+		case inner[i] == '|' && depth == 0:
+			parts = append(parts, inner[last:i])
+			last = i + 1
+		}
+	}
+	return append(parts, inner[last:])
+}
+
+func parseTemplate(inner string) template {
+	parts := splitParams(inner)
+	t := template{
+		name:   templateName(parts[0]),
+		params: map[string]string{},
+	}
+	position := 0
+	for _, part := range parts[1:] {
+		if i := strings.Index(part, "="); i >= 0 && !strings.ContainsAny(part[:i], "{[") {
+			t.params[strings.ToLower(strings.TrimSpace(part[:i]))] = strings.TrimSpace(part[i+1:])
+			continue
+		}
+		position++
+		t.params[strconv.Itoa(position)] = strings.TrimSpace(part)
+	}
+	return t
+}
+
+// templateName normalizes a template name, so that
+// {{Song Header}}, {{song_header}} and {{SongHeader}} are the same.
+func templateName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimPrefix(name, "template:")
+	return strings.NewReplacer(" ", "", "_", "").Replace(name)
+}
+
+// param returns the first non-empty parameter of t named by keys, as plain text.
+func (t template) param(keys ...string) string {
+	for _, key := range keys {
+		if value := plainText(t.params[key]); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// metadata returns the metadata held by a song template.
+// {{Song}} also takes the album, with its year in parentheses,
+// and the artist as its first two unnamed parameters.
+func (t template) metadata() Metadata {
+	var m Metadata
+	album := t.param("album")
+	m.Artist = t.param("artist")
+	if t.name == "song" {
+		if album == "" {
+			album = t.param("1")
+		}
+		if m.Artist == "" {
+			m.Artist = t.param("2")
+		}
+	}
+	if match := albumYearPattern.FindStringSubmatch(album); match != nil {
+		album = match[1]
+		m.Year, _ = strconv.Atoi(match[2])
+	}
+	m.Album = album
+	m.Song = t.param("song", "title")
+	if year := yearPattern.FindString(t.param("year")); year != "" {
+		m.Year, _ = strconv.Atoi(year)
+	}
+	if number := numberPattern.FindString(t.param("track", "tracknumber", "trackno")); number != "" {
+		m.TrackNumber, _ = strconv.Atoi(number)
+	}
+	m.Language = t.param("language")
+	for _, key := range []string{"featuring", "feat", "featured"} {
+		m.Featuring = appendNames(m.Featuring, t.param(key))
+	}
+	for _, key := range []string{"writer", "writers", "songwriter", "songwriters", "lyricist", "lyricists", "lyrics", "composer", "composers", "music"} {
+		m.Writers = appendNames(m.Writers, t.param(key))
+	}
+	return m
+}
+
+// appendNames appends the names listed by value to names, skipping duplicates.
+func appendNames(names []string, value string) []string {
+	for _, name := range namesPattern.Split(value, -1) {
+		if name != "" && !contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// merge fills the empty fields of m with those of other.
+func (m *Metadata) merge(other Metadata) {
+	if m.Artist == "" {
+		m.Artist = other.Artist
+	}
+	if m.Song == "" {
+		m.Song = other.Song
+	}
+	if m.Album == "" {
+		m.Album = other.Album
+	}
+	if m.Year == 0 {
+		m.Year = other.Year
+	}
+	if m.TrackNumber == 0 {
+		m.TrackNumber = other.TrackNumber
+	}
+	if m.Language == "" {
+		m.Language = other.Language
+	}
+	for _, name := range other.Featuring {
+		if !contains(m.Featuring, name) {
+			m.Featuring = append(m.Featuring, name)
+		}
+	}
+	for _, name := range other.Writers {
+		if !contains(m.Writers, name) {
+			m.Writers = append(m.Writers, name)
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package wikitext
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_templates(t *testing.T) {
+	source := "Intro {{Song|[[A:B (2001)|B]] (2001)|A|note={{small|x}}}} middle {{Instrumental}} {{broken"
+	want := []template{
+		{
+			name:   "song",
+			params: map[string]string{"1": "[[A:B (2001)|B]] (2001)", "2": "A", "note": "{{small|x}}"},
+			start:  6,
+			end:    57,
+		},
+		{
+			name:   "instrumental",
+			params: map[string]string{},
+			start:  65,
+			end:    81,
+		},
+	}
+	if got := templates(source); !reflect.DeepEqual(got, want) {
+		t.Errorf("templates() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_templateName(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "should ignore case and spaces", in: " Song Header ", want: "songheader"},
+		{name: "should ignore underscores", in: "Song_Footer", want: "songfooter"},
+		{name: "should drop the namespace", in: "Template:Instrumental", want: "instrumental"},
+	}
+	for _, tt := range tests {
+		if got := templateName(tt.in); got != tt.want {
+			t.Errorf("%q. templateName() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMetadata_merge(t *testing.T) {
+	m := Metadata{Artist: "Blackfield", Writers: []string{"Steven Wilson"}}
+	m.merge(Metadata{Artist: "Other", Album: "II", Year: 2007, Writers: []string{"Steven Wilson", "Aviv Geffen"}})
+	want := Metadata{Artist: "Blackfield", Album: "II", Year: 2007, Writers: []string{"Steven Wilson", "Aviv Geffen"}}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("Metadata.merge() = %+v, want %+v", m, want)
+	}
+}
@@ -0,0 +1,199 @@
+// Package wikitext parses the wikitext source of lyrics wiki pages
+// into their lyrics and the metadata of their song.
+//
+// It understands the subset of MediaWiki markup used by lyrics wikis:
+// <lyrics> tags, the {{Song}}, {{SongHeader}} and {{SongFooter}} templates,
+// italic and bold text, <br> line breaks, links, comments
+// and {{Instrumental}} markers. Anything else is dropped or kept as text.
+// Italic and bold text is marked with two and three apostrophes:
+//
+//	''italic'' and '''bold''' text
+package wikitext
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Segment is a run of text of a line sharing the same formatting.
+type Segment struct {
+	Text   string
+	Italic bool
+	Bold   bool
+}
+
+// Line is a line of lyrics, made of its formatted segments.
+type Line []Segment
+
+// String returns the text of the line without formatting.
+func (l Line) String() string {
+	var text strings.Builder
+	for _, segment := range l {
+		text.WriteString(segment.Text)
+	}
+	return text.String()
+}
+
+// Stanza is a group of lines separated from the others by a blank line.
+type Stanza []Line
+
+// Metadata describes the song of a page, as given by its song templates.
+type Metadata struct {
+	Artist      string
+	Song        string
+	Album       string
+	Year        int
+	TrackNumber int
+	Featuring   []string
+	Writers     []string
+	Language    string
+}
+
+// Page is a parsed lyrics page.
+type Page struct {
+	Metadata Metadata
+	// Stanzas holds the lyrics of every <lyrics> block of the page.
+	Stanzas []Stanza
+	// Instrumental reports whether the page marks the song as instrumental.
+	Instrumental bool
+}
+
+// Text returns the lyrics of the page as plain text,
+// with stanzas separated by blank lines.
+func (p Page) Text() string {
+	stanzas := make([]string, len(p.Stanzas))
+	for i, stanza := range p.Stanzas {
+		lines := make([]string, len(stanza))
+		for j, line := range stanza {
+			lines[j] = line.String()
+		}
+		stanzas[i] = strings.Join(lines, "\n")
+	}
+	return strings.Join(stanzas, "\n\n")
+}
+
+var (
+	commentPattern = regexp.MustCompile(`(?s)<!--.*?(-->|$)`)
+	lyricsPattern  = regexp.MustCompile(`(?is)<lyrics?>(.*?)(</lyrics?>|$)`)
+	breakPattern   = regexp.MustCompile(`(?i)<\s*/?\s*br\s*/?\s*>`)
+	tagPattern     = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	linkPattern    = regexp.MustCompile(`\[\[([^\]|]*)(?:\|([^\]]*))?\]\]`)
+)
+
+// Parse parses the wikitext of a lyrics page.
+// It never fails: markup it does not understand is kept as text.
+func Parse(source string) Page {
+	source = commentPattern.ReplaceAllString(source, "")
+	var page Page
+	for _, t := range templates(source) {
+		switch t.name {
+		case "instrumental":
+			page.Instrumental = true
+		case "song", "songheader", "songfooter":
+			page.Metadata.merge(t.metadata())
+		}
+	}
+	for _, match := range lyricsPattern.FindAllStringSubmatch(source, -1) {
+		page.Stanzas = append(page.Stanzas, parseLyrics(match[1])...)
+	}
+	return page
+}
+
+// parseLyrics parses the content of a <lyrics> block into stanzas.
+func parseLyrics(block string) []Stanza {
+	block = breakPattern.ReplaceAllString(block, "\n")
+	block = removeTemplates(block)
+	block = replaceLinks(block)
+	block = tagPattern.ReplaceAllString(block, "")
+
+	var stanzas []Stanza
+	var stanza Stanza
+	for _, text := range strings.Split(block, "\n") {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			if len(stanza) > 0 {
+				stanzas = append(stanzas, stanza)
+				stanza = nil
+			}
+			continue
+		}
+		stanza = append(stanza, parseLine(text))
+	}
+	if len(stanza) > 0 {
+		stanzas = append(stanzas, stanza)
+	}
+	return stanzas
+}
+
+// parseLine splits a line into segments at its runs of apostrophes,
+// toggling italic for two of them, bold for three and both for five.
+// Like in MediaWiki, formatting does not carry over to the next line.
+func parseLine(text string) Line {
+	var line Line
+	var italic, bold bool
+	var segment strings.Builder
+	flush := func() {
+		if segment.Len() > 0 {
+			line = append(line, Segment{Text: html.UnescapeString(segment.String()), Italic: italic, Bold: bold})
+			segment.Reset()
+		}
+	}
+	for i := 0; i < len(text); {
+		if text[i] != '\'' {
+			segment.WriteByte(text[i])
+			i++
+			continue
+		}
+		n := 1
+		for i+n < len(text) && text[i+n] == '\'' {
+			n++
+		}
+		switch {
+		case n == 1:
+			segment.WriteByte('\'')
+		case n == 2:
+			flush()
+			italic = !italic
+		case n == 3:
+			flush()
+			bold = !bold
+		case n == 4:
+			segment.WriteByte('\'')
+			flush()
+			bold = !bold
+		default:
+			segment.WriteString(strings.Repeat("'", n-5))
+			flush()
+			italic, bold = !italic, !bold
+		}
+		i += n
+	}
+	flush()
+	return line
+}
+
+// replaceLinks replaces links with their label,
+// or their title without the artist when they have none.
+func replaceLinks(text string) string {
+	return linkPattern.ReplaceAllStringFunc(text, func(link string) string {
+		match := linkPattern.FindStringSubmatch(link)
+		if match[2] != "" {
+			return match[2]
+		}
+		title := match[1]
+		if i := strings.Index(title, ":"); i >= 0 {
+			title = title[i+1:]
+		}
+		return title
+	})
+}
+
+// plainText returns a template value without markup.
+func plainText(value string) string {
+	value = breakPattern.ReplaceAllString(value, "\n")
+	value = removeTemplates(value)
+	value = replaceLinks(value)
+	value = tagPattern.ReplaceAllString(value, "")
+	return strings.TrimSpace(parseLine(value).String())
+}
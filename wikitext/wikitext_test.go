@@ -0,0 +1,141 @@
+package wikitext
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   Page
+	}{
+		{
+			name: "should parse the lyrics and metadata of a song page",
+			source: "{{Song|[[Blackfield:Blackfield (2004)|Blackfield]] (2004)|Blackfield}}\n" +
+				"<!-- lyrics reviewed -->\n" +
+				"<lyrics>\nPain\nCan&#39;t run<br />\n\n''(Go away)''\n</lyrics>\n" +
+				"{{SongFooter\n|song=Pain\n|track=3\n|feat=[[Aviv Geffen]]\n|language=English\n|music=Steven Wilson, Aviv Geffen\n}}",
+			want: Page{
+				Metadata: Metadata{
+					Artist:      "Blackfield",
+					Song:        "Pain",
+					Album:       "Blackfield",
+					Year:        2004,
+					TrackNumber: 3,
+					Featuring:   []string{"Aviv Geffen"},
+					Writers:     []string{"Steven Wilson", "Aviv Geffen"},
+					Language:    "English",
+				},
+				Stanzas: []Stanza{
+					{{{Text: "Pain"}}, {{Text: "Can't run"}}},
+					{{{Text: "(Go away)", Italic: true}}},
+				},
+			},
+		},
+		{
+			name: "should read the named parameters of song headers",
+			source: "{{SongHeader\n|song=Hoppípolla\n|artist=[[Sigur Rós]]\n|album=Takk...\n|year=2005\n" +
+				"|writers=Jón Þór Birgisson and Georg Hólm\n}}\n<lyrics>Brosandi</lyrics>",
+			want: Page{
+				Metadata: Metadata{
+					Artist:  "Sigur Rós",
+					Song:    "Hoppípolla",
+					Album:   "Takk...",
+					Year:    2005,
+					Writers: []string{"Jón Þór Birgisson", "Georg Hólm"},
+				},
+				Stanzas: []Stanza{{{{Text: "Brosandi"}}}},
+			},
+		},
+		{
+			name:   "should join the stanzas of every lyrics block",
+			source: "<lyrics>One<br>Two</lyrics>\nChorus\n<lyric>Three</br>Four</lyric>",
+			want: Page{
+				Stanzas: []Stanza{
+					{{{Text: "One"}}, {{Text: "Two"}}},
+					{{{Text: "Three"}}, {{Text: "Four"}}},
+				},
+			},
+		},
+		{
+			name:   "should mark instrumental songs",
+			source: "{{Song|Takk... (2005)|Sigur Rós}}\n<lyrics>{{Instrumental}}</lyrics>",
+			want: Page{
+				Metadata:     Metadata{Artist: "Sigur Rós", Album: "Takk...", Year: 2005},
+				Instrumental: true,
+			},
+		},
+		{
+			name:   "should drop comments, tags and templates from lyrics",
+			source: "<lyrics><!-- verse -->Hello <span class='x'>[[Sigur Rós:Hoppípolla|world]]</span>{{note|1}}\n<!-- unfinished</lyrics>",
+			want: Page{
+				Stanzas: []Stanza{{{{Text: "Hello world"}}}},
+			},
+		},
+		{
+			name:   "should find nothing in pages without lyrics",
+			source: "'''Pain''' may refer to several songs.",
+			want:   Page{},
+		},
+	}
+	for _, tt := range tests {
+		if got := Parse(tt.source); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. Parse() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func Test_parseLine(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Line
+	}{
+		{
+			name: "should keep single apostrophes",
+			text: "Rock 'n' roll",
+			want: Line{{Text: "Rock 'n' roll"}},
+		},
+		{
+			name: "should split italic and bold segments",
+			text: "I ''said'' '''no''' '''''way'''''",
+			want: Line{
+				{Text: "I "},
+				{Text: "said", Italic: true},
+				{Text: " "},
+				{Text: "no", Bold: true},
+				{Text: " "},
+				{Text: "way", Italic: true, Bold: true},
+			},
+		},
+		{
+			name: "should keep the extra apostrophe of four",
+			text: "''''Bold''' start",
+			want: Line{{Text: "'"}, {Text: "Bold", Bold: true}, {Text: " start"}},
+		},
+		{
+			name: "should decode entities",
+			text: "Salt &amp; pepper &quot;now&quot;",
+			want: Line{{Text: `Salt & pepper "now"`}},
+		},
+	}
+	for _, tt := range tests {
+		if got := parseLine(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. parseLine() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPage_Text(t *testing.T) {
+	page := Page{
+		Stanzas: []Stanza{
+			{{{Text: "I "}, {Text: "said", Italic: true}}, {{Text: "Two"}}},
+			{{{Text: "Three"}}},
+		},
+	}
+	if got, want := page.Text(), "I said\nTwo\n\nThree"; got != want {
+		t.Errorf("Page.Text() = %q, want %q", got, want)
+	}
+}